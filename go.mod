@@ -3,14 +3,25 @@ module github.com/cymon1997/go-client
 go 1.18
 
 require (
+	github.com/fxamacker/cbor/v2 v2.7.0
 	github.com/google/go-querystring v1.1.0
 	github.com/stretchr/testify v1.8.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	go.yaml.in/yaml/v3 v3.0.5
+	golang.org/x/net v0.35.0
 	gopkg.in/h2non/gock.v1 v1.1.2
+	nhooyr.io/websocket v1.8.7
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/h2non/parth v0.0.0-20190131123155-b4df798d6542 // indirect
+	github.com/klauspost/compress v1.10.3 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,101 @@
+// Package grpc provide gRPC connections built from Config, sharing retry,
+// logging & metrics hooks with the http package
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// New create connection from cfg, the connection is established lazily on first call.
+// Interceptors order from outermost: timeout, headers, retry, cfg interceptors, logging
+func New(cfg Config) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := newCallLogger(cfg.Log)
+	unary := []grpc.UnaryClientInterceptor{
+		timeoutInterceptor(cfg.Timeout),
+		headersUnaryInterceptor(cfg.Headers),
+		cfg.Retry.unaryInterceptor(),
+	}
+	unary = append(unary, cfg.UnaryInterceptors...)
+	unary = append(unary, logger.unaryInterceptor())
+
+	stream := []grpc.StreamClientInterceptor{
+		headersStreamInterceptor(cfg.Headers),
+		cfg.Retry.streamInterceptor(),
+	}
+	stream = append(stream, cfg.StreamInterceptors...)
+	stream = append(stream, logger.streamInterceptor())
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	}
+	return grpc.NewClient(cfg.Host, append(opts, cfg.DialOptions...)...)
+}
+
+func transportCredentials(cfg Config) (credentials.TransportCredentials, error) {
+	if cfg.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+	if cfg.TLS == nil {
+		return credentials.NewClientTLSFromCert(nil, ""), nil
+	}
+	tc, err := cfg.TLS.Build()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tc), nil
+}
+
+// timeoutInterceptor bound unary calls without earlier deadline, 0 means no timeout
+func timeoutInterceptor(timeout int) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func headersUnaryInterceptor(headers map[string]string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withHeaders(ctx, headers), method, req, reply, cc, opts...)
+	}
+}
+
+func headersStreamInterceptor(headers map[string]string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withHeaders(ctx, headers), desc, cc, method, opts...)
+	}
+}
+
+// withHeaders add headers to outgoing metadata, metadata already set on ctx take precedence
+func withHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	kv := make([]string, 0, len(headers)*2)
+	for k, v := range headers {
+		if len(md.Get(k)) == 0 {
+			kv = append(kv, k, v)
+		}
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
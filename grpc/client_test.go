@@ -0,0 +1,257 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer fail the first failures calls with code, then serve SERVING
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	mu       sync.Mutex
+	failures int
+	code     codes.Code
+	calls    int
+	md       metadata.MD
+	deadline bool
+}
+
+func (s *healthServer) record(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	_, s.deadline = ctx.Deadline()
+	if s.calls <= s.failures {
+		return status.Error(s.code, "failed")
+	}
+	return nil
+}
+
+func (s *healthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	if err := s.record(stream.Context()); err != nil {
+		return err
+	}
+	for _, st := range []grpc_health_v1.HealthCheckResponse_ServingStatus{
+		grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		grpc_health_v1.HealthCheckResponse_SERVING,
+	} {
+		if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: st}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newConn serve srv over bufconn & create connection from cfg
+func newConn(t *testing.T, srv *healthServer, cfg Config) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, srv)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	cfg.Host = "passthrough:///bufnet"
+	cfg.Insecure = true
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	conn, err := New(cfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+type logEntry struct {
+	level  httpClient.LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+type memoryLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (m *memoryLogger) Log(_ context.Context, level httpClient.LogLevel, msg string, fields ...httpClient.Field) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := logEntry{level: level, msg: msg, fields: map[string]interface{}{}}
+	for _, f := range fields {
+		entry.fields[f.Key] = f.Value
+	}
+	m.entries = append(m.entries, entry)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("headers & timeout", func(t *testing.T) {
+		srv := &healthServer{}
+		conn := newConn(t, srv, Config{
+			Timeout: 3000,
+			Headers: map[string]string{"X-Api-Key": "key", "X-Tenant": "default"},
+		})
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant", "custom")
+		got, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, got.Status)
+		assert.Equal(t, []string{"key"}, srv.md.Get("x-api-key"))
+		assert.Equal(t, []string{"custom"}, srv.md.Get("x-tenant"))
+		assert.True(t, srv.deadline)
+	})
+
+	t.Run("invalid tls", func(t *testing.T) {
+		_, err := New(Config{
+			Host: "localhost:9000",
+			TLS:  &httpClient.TLSConfig{CAFile: "testdata/missing.pem"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("retry unavailable", func(t *testing.T) {
+		srv := &healthServer{failures: 2, code: codes.Unavailable}
+		var attempts []int
+		conn := newConn(t, srv, Config{
+			Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: 1},
+			UnaryInterceptors: []grpc.UnaryClientInterceptor{
+				func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+					invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+					attempts = append(attempts, Attempt(ctx))
+					return invoker(ctx, method, req, reply, cc, opts...)
+				},
+			},
+		})
+
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, srv.calls)
+		assert.Equal(t, []int{1, 2, 3}, attempts)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		srv := &healthServer{failures: 5, code: codes.Unavailable}
+		conn := newConn(t, srv, Config{Retry: &RetryPolicy{MaxAttempts: 2, BaseDelay: 1}})
+
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 2, srv.calls)
+	})
+
+	t.Run("non-retryable code", func(t *testing.T) {
+		srv := &healthServer{failures: 1, code: codes.InvalidArgument}
+		conn := newConn(t, srv, Config{Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: 1}})
+
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 1, srv.calls)
+	})
+
+	t.Run("stream creation", func(t *testing.T) {
+		srv := &healthServer{}
+		var attempts int
+		conn := newConn(t, srv, Config{
+			Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: 1},
+			StreamInterceptors: []grpc.StreamClientInterceptor{
+				func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+					streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					attempts++
+					if Attempt(ctx) == 1 {
+						return nil, status.Error(codes.Unavailable, "connecting")
+					}
+					return streamer(ctx, desc, cc, method, opts...)
+				},
+			},
+		})
+
+		stream, err := grpc_health_v1.NewHealthClient(conn).Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err)
+		got, err := stream.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, got.Status)
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func Test_callLogger(t *testing.T) {
+	t.Run("unary", func(t *testing.T) {
+		srv := &healthServer{failures: 1, code: codes.Unavailable}
+		logger := &memoryLogger{}
+		conn := newConn(t, srv, Config{
+			Headers: map[string]string{"Authorization": "Bearer token", "X-Tenant": "default"},
+			Retry:   &RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+			Log: &httpClient.LogConfig{
+				Logger:     logger,
+				Level:      httpClient.LevelInfo,
+				LogHeaders: true,
+			},
+		})
+
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err)
+
+		assert.Len(t, logger.entries, 4)
+		for i, entry := range logger.entries {
+			assert.Equal(t, "/grpc.health.v1.Health/Check", entry.fields["method"])
+			assert.Equal(t, i/2+1, entry.fields["attempt"])
+		}
+		assert.Equal(t, "grpc request", logger.entries[0].msg)
+		assert.Equal(t, map[string]string{
+			"authorization": redacted,
+			"x-tenant":      "default",
+		}, logger.entries[0].fields["metadata"])
+		assert.Equal(t, httpClient.LevelWarn, logger.entries[1].level)
+		assert.Equal(t, "Unavailable", logger.entries[1].fields["code"])
+		assert.NotNil(t, logger.entries[1].fields["error"])
+		assert.Equal(t, httpClient.LevelInfo, logger.entries[3].level)
+		assert.Equal(t, "OK", logger.entries[3].fields["code"])
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		logger := &memoryLogger{}
+		conn := newConn(t, &healthServer{}, Config{
+			Log: &httpClient.LogConfig{Logger: logger, Level: httpClient.LevelDebug},
+		})
+
+		stream, err := grpc_health_v1.NewHealthClient(conn).Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err)
+		var received int
+		for {
+			if _, err := stream.Recv(); err != nil {
+				break
+			}
+			received++
+		}
+		assert.Equal(t, 2, received)
+
+		assert.Len(t, logger.entries, 2)
+		assert.Equal(t, "grpc stream", logger.entries[0].msg)
+		assert.Equal(t, "grpc stream closed", logger.entries[1].msg)
+		assert.Equal(t, httpClient.LevelDebug, logger.entries[1].level)
+		assert.Equal(t, "OK", logger.entries[1].fields["code"])
+	})
+}
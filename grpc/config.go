@@ -0,0 +1,32 @@
+package grpc
+
+import (
+	httpClient "github.com/cymon1997/go-client/http"
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	// Host target of the connection, e.g. localhost:9000 or dns:///service:9000
+	Host string
+	// Timeout of unary calls in milliseconds including retries, applied when ctx has no earlier deadline.
+	// Streams are not bounded by Timeout
+	Timeout int
+	// TLS custom CA, client certificate & TLS parameters, nil means system defaults.
+	// Certificates are loaded once when the connection is created
+	TLS *httpClient.TLSConfig
+	// Insecure use plaintext connection, TLS is ignored
+	Insecure bool
+	// Headers sent as metadata of every call
+	Headers map[string]string
+	// Retry policy applied to unary calls & stream creation, nil means no retry
+	Retry *RetryPolicy
+	// Log call logging sharing http LogConfig, LogHeaders & RedactHeaders apply to metadata,
+	// nil means no logging
+	Log *httpClient.LogConfig
+	// UnaryInterceptors run once per attempt in registration order, inside retry
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+	// StreamInterceptors run once per attempt in registration order, inside retry
+	StreamInterceptors []grpc.StreamClientInterceptor
+	// DialOptions extra options of the connection, e.g. grpc.WithContextDialer
+	DialOptions []grpc.DialOption
+}
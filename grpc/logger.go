@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// redacted replace value of sensitive metadata
+const redacted = "REDACTED"
+
+// callLogger log every attempt of calls sent through a connection
+type callLogger struct {
+	cfg     httpClient.LogConfig
+	headers map[string]bool
+}
+
+func newCallLogger(cfg *httpClient.LogConfig) *callLogger {
+	if cfg == nil || cfg.Logger == nil {
+		return nil
+	}
+	headers := cfg.RedactHeaders
+	if headers == nil {
+		headers = httpClient.DefaultRedactHeaders
+	}
+	l := &callLogger{cfg: *cfg, headers: make(map[string]bool, len(headers))}
+	for _, h := range headers {
+		l.headers[strings.ToLower(h)] = true
+	}
+	return l
+}
+
+func (l *callLogger) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if l == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		fields := l.request(ctx, "grpc request", method, cc)
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		l.response(ctx, "grpc response", fields, start, err)
+		return err
+	}
+}
+
+// streamInterceptor log stream creation & completion, see ObserveStream
+func (l *callLogger) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if l == nil {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		fields := l.request(ctx, "grpc stream", method, cc)
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			l.response(ctx, "grpc stream closed", fields, start, err)
+			return nil, err
+		}
+		return ObserveStream(stream, desc, func(err error) {
+			l.response(ctx, "grpc stream closed", fields, start, err)
+		}), nil
+	}
+}
+
+func (l *callLogger) request(ctx context.Context, msg, method string, cc *grpc.ClientConn) []httpClient.Field {
+	fields := []httpClient.Field{
+		{Key: "method", Value: method},
+		{Key: "target", Value: cc.Target()},
+		{Key: "attempt", Value: Attempt(ctx)},
+	}
+	if l.cfg.LogHeaders {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		l.cfg.Logger.Log(ctx, l.cfg.Level, msg,
+			append(fields, httpClient.Field{Key: "metadata", Value: l.redactMetadata(md)})...)
+	} else {
+		l.cfg.Logger.Log(ctx, l.cfg.Level, msg, fields...)
+	}
+	return fields
+}
+
+func (l *callLogger) response(ctx context.Context, msg string, fields []httpClient.Field, start time.Time, err error) {
+	fields = append(fields,
+		httpClient.Field{Key: "latency", Value: time.Since(start)},
+		httpClient.Field{Key: "code", Value: status.Code(err).String()},
+	)
+	level := l.cfg.Level
+	if isServerError(err) && level < httpClient.LevelWarn {
+		level = httpClient.LevelWarn
+	}
+	if err != nil {
+		fields = append(fields, httpClient.Field{Key: "error", Value: err.Error()})
+	}
+	l.cfg.Logger.Log(ctx, level, msg, fields...)
+}
+
+func (l *callLogger) redactMetadata(md metadata.MD) map[string]string {
+	res := make(map[string]string, len(md))
+	for k, v := range md {
+		if l.headers[strings.ToLower(k)] {
+			res[k] = redacted
+		} else {
+			res[k] = strings.Join(v, ",")
+		}
+	}
+	return res
+}
+
+// isServerError check whether err is a transport or server failure, the gRPC
+// counterpart of http 5xx
+func isServerError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// ObserveStream wrap stream so done is called once when it completes, i.e. closed by
+// server (nil error), its single response received (nil error) or failed.
+// Useful for stream interceptors recording the outcome of a stream
+func ObserveStream(stream grpc.ClientStream, desc *grpc.StreamDesc, done func(err error)) grpc.ClientStream {
+	return &observedStream{ClientStream: stream, desc: desc, done: done}
+}
+
+type observedStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	once sync.Once
+	done func(err error)
+}
+
+func (s *observedStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *observedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	case !s.desc.ServerStreams:
+		// single response completes the stream
+		s.finish(nil)
+	}
+	return err
+}
+
+func (s *observedStream) finish(err error) {
+	s.once.Do(func() {
+		s.done(err)
+	})
+}
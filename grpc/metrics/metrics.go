@@ -0,0 +1,130 @@
+// Package metrics provide Prometheus RED metrics for grpc connections
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	grpcClient "github.com/cymon1997/go-client/grpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Options of collectors, all fields are optional
+type Options struct {
+	Namespace string
+	Subsystem string
+	// Buckets of call duration histogram in seconds, default prometheus.DefBuckets
+	Buckets []float64
+	// ConstLabels attached to all collectors, e.g. client name
+	ConstLabels prometheus.Labels
+}
+
+// Metrics hold collectors of client calls
+type Metrics struct {
+	duration *prometheus.HistogramVec
+	calls    *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+	retries  *prometheus.CounterVec
+}
+
+// New create & register collectors on reg
+func New(reg prometheus.Registerer, opts Options) (*Metrics, error) {
+	m := &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "grpc_client_call_duration_seconds",
+			Help:        "Duration of outbound grpc calls.",
+			Buckets:     opts.Buckets,
+			ConstLabels: opts.ConstLabels,
+		}, []string{"target", "service", "method", "code"}),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "grpc_client_calls_total",
+			Help:        "Total outbound grpc calls.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"target", "service", "method", "code"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "grpc_client_calls_in_flight",
+			Help:        "Outbound grpc calls currently in flight.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"target"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "grpc_client_retries_total",
+			Help:        "Total retry attempts of outbound grpc calls.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"target", "service", "method"}),
+	}
+	collectors := []prometheus.Collector{m.duration, m.calls, m.inFlight, m.retries}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			// leave reg as it was so New can be retried
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// UnaryInterceptor record duration, code, in-flight & retry metrics of every attempt,
+// register it in Config.UnaryInterceptors
+func (m *Metrics) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done := m.start(ctx, cc.Target(), method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		done(err)
+		return err
+	}
+}
+
+// StreamInterceptor record metrics of every stream until it completes, see grpc.ObserveStream,
+// register it in Config.StreamInterceptors
+func (m *Metrics) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		done := m.start(ctx, cc.Target(), method)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			done(err)
+			return nil, err
+		}
+		return grpcClient.ObserveStream(stream, desc, done), nil
+	}
+}
+
+// start record call start & return func recording its result
+func (m *Metrics) start(ctx context.Context, target, fullMethod string) func(err error) {
+	service, method := splitMethod(fullMethod)
+	if grpcClient.Attempt(ctx) > 1 {
+		m.retries.WithLabelValues(target, service, method).Inc()
+	}
+	inFlight := m.inFlight.WithLabelValues(target)
+	inFlight.Inc()
+	start := time.Now()
+	return func(err error) {
+		inFlight.Dec()
+		code := status.Code(err).String()
+		m.duration.WithLabelValues(target, service, method, code).Observe(time.Since(start).Seconds())
+		m.calls.WithLabelValues(target, service, method, code).Inc()
+	}
+}
+
+// splitMethod split /package.Service/Method into service & method
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
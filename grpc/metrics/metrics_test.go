@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	grpcClient "github.com/cymon1997/go-client/grpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newConn(t *testing.T, cfg grpcClient.Config) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	cfg.Host = "passthrough:///bufnet"
+	cfg.Insecure = true
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	conn, err := grpcClient.New(cfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestNew(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := New(reg, Options{})
+	assert.Nil(t, err)
+
+	// duplicate registration
+	_, err = New(reg, Options{})
+	assert.Error(t, err)
+
+	// failed registration leave no collector behind
+	reg = prometheus.NewRegistry()
+	_, err = New(&failingRegisterer{Registerer: reg, n: 2}, Options{})
+	assert.Error(t, err)
+	_, err = New(reg, Options{})
+	assert.Nil(t, err)
+}
+
+// failingRegisterer fail every registration after the first n
+type failingRegisterer struct {
+	prometheus.Registerer
+	n int
+}
+
+func (r *failingRegisterer) Register(c prometheus.Collector) error {
+	if r.n == 0 {
+		return errors.New("registry full")
+	}
+	r.n--
+	return r.Registerer.Register(c)
+}
+
+func TestMetrics_UnaryInterceptor(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(reg, Options{Namespace: "sample"})
+	assert.Nil(t, err)
+
+	conn := newConn(t, grpcClient.Config{
+		Retry: &grpcClient.RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+		UnaryInterceptors: []grpc.UnaryClientInterceptor{
+			m.UnaryInterceptor(),
+			// fail first attempt
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+				invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				if grpcClient.Attempt(ctx) == 1 {
+					return status.Error(codes.Unavailable, "unavailable")
+				}
+				return invoker(ctx, method, req, reply, cc, opts...)
+			},
+		},
+	})
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP sample_grpc_client_calls_total Total outbound grpc calls.
+# TYPE sample_grpc_client_calls_total counter
+sample_grpc_client_calls_total{code="OK",method="Check",service="grpc.health.v1.Health",target="passthrough:///bufnet"} 1
+sample_grpc_client_calls_total{code="Unavailable",method="Check",service="grpc.health.v1.Health",target="passthrough:///bufnet"} 1
+# HELP sample_grpc_client_retries_total Total retry attempts of outbound grpc calls.
+# TYPE sample_grpc_client_retries_total counter
+sample_grpc_client_retries_total{method="Check",service="grpc.health.v1.Health",target="passthrough:///bufnet"} 1
+# HELP sample_grpc_client_calls_in_flight Outbound grpc calls currently in flight.
+# TYPE sample_grpc_client_calls_in_flight gauge
+sample_grpc_client_calls_in_flight{target="passthrough:///bufnet"} 0
+`), "sample_grpc_client_calls_total", "sample_grpc_client_retries_total", "sample_grpc_client_calls_in_flight")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.duration.WithLabelValues(
+		"passthrough:///bufnet", "grpc.health.v1.Health", "Check", "OK").(prometheus.Histogram)))
+}
+
+func TestMetrics_StreamInterceptor(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(reg, Options{})
+	assert.Nil(t, err)
+
+	conn := newConn(t, grpcClient.Config{
+		StreamInterceptors: []grpc.StreamClientInterceptor{m.StreamInterceptor()},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.inFlight.WithLabelValues("passthrough:///bufnet")))
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.inFlight.WithLabelValues("passthrough:///bufnet")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.calls.WithLabelValues(
+		"passthrough:///bufnet", "grpc.health.v1.Health", "Watch", "Canceled")))
+}
+
+func Test_splitMethod(t *testing.T) {
+	service, method := splitMethod("/grpc.health.v1.Health/Check")
+	assert.Equal(t, "grpc.health.v1.Health", service)
+	assert.Equal(t, "Check", method)
+
+	service, method = splitMethod("Check")
+	assert.Equal(t, "unknown", service)
+	assert.Equal(t, "Check", method)
+}
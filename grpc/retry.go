@@ -0,0 +1,124 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRetryCodes used when RetryPolicy.RetryCodes is nil
+var DefaultRetryCodes = []codes.Code{
+	codes.Unavailable,
+}
+
+// RetryPolicy decide how a failed call should be retried, backoff is the same
+// exponential full jitter as http.RetryPolicy
+type RetryPolicy struct {
+	// MaxAttempts including the first attempt, <= 1 means no retry
+	MaxAttempts int
+	// BaseDelay of backoff in milliseconds
+	BaseDelay int
+	// MaxDelay of backoff in milliseconds, 0 means no limit
+	MaxDelay int
+	// RetryCodes status codes to retry, nil means DefaultRetryCodes
+	RetryCodes []codes.Code
+}
+
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts <= 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) shouldRetry(err error) bool {
+	retryCodes := p.RetryCodes
+	if retryCodes == nil {
+		retryCodes = DefaultRetryCodes
+	}
+	code := status.Code(err)
+	for _, c := range retryCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	return (&httpClient.RetryPolicy{BaseDelay: p.BaseDelay, MaxDelay: p.MaxDelay}).Backoff(retry)
+}
+
+// retry call fn until it succeeds, the error is not retryable, attempts are exhausted or ctx is done
+func (p *RetryPolicy) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := p.attempts()
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if sleepErr := sleep(ctx, p.backoff(attempt-1)); sleepErr != nil {
+				return err
+			}
+		}
+		err = fn(withAttempt(ctx, attempt))
+		if err == nil || attempt == attempts || !p.shouldRetry(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// unaryInterceptor retry unary calls, attempt number is available to inner interceptors via Attempt
+func (p *RetryPolicy) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return p.retry(ctx, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// streamInterceptor retry stream creation only, errors of messages already sent or received
+// are returned as is since the stream cannot be replayed
+func (p *RetryPolicy) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		err := p.retry(ctx, func(ctx context.Context) error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		})
+		return stream, err
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type attemptKey struct{}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// Attempt return attempt number of the call starting from 1,
+// only available within call context seen by Config interceptors, 0 otherwise
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/base64"
+	"net/http"
+)
+
+// Authenticator add credentials to every outgoing request, it is invoked once per attempt
+// after middlewares so credentials are never stored in shared headers.
+// Set it using Config.Auth, override per request using Request.WithAuth
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapt ordinary function as Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// responseObserver is implemented by authenticators depending on server responses,
+// e.g. discard cached credentials on 401 or learn server clock
+type responseObserver interface {
+	observe(req *http.Request, resp *http.Response)
+}
+
+// BasicAuth authenticate using HTTP Basic auth
+func BasicAuth(username, password string) Authenticator {
+	value := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", value)
+		return nil
+	})
+}
+
+// BearerToken authenticate using static bearer token
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKeyHeader authenticate using API key sent in header, e.g. X-API-Key
+func APIKeyHeader(header, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set(header, key)
+		return nil
+	})
+}
+
+// APIKeyQuery authenticate using API key sent as query param, e.g. api_key.
+// Add param to LogConfig.RedactQuery when it is not part of DefaultRedactQuery
+func APIKeyQuery(param, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		q := req.URL.Query()
+		q.Set(param, key)
+		req.URL.RawQuery = q.Encode()
+		return nil
+	})
+}
+
+// authenticate wrap next with auth, nil auth means no authentication
+func authenticate(next Doer, auth Authenticator) Doer {
+	if auth == nil {
+		return next
+	}
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if err := auth.Authenticate(req); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		resp, err := next.Do(req)
+		if o, ok := auth.(responseObserver); ok && err == nil {
+			o.observe(req, resp)
+		}
+		return resp, err
+	})
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestAuthenticator(t *testing.T) {
+	tests := []struct {
+		name string
+		auth Authenticator
+		mock func()
+	}{
+		{
+			name: "basic",
+			auth: BasicAuth("user", "secret"),
+			mock: func() {
+				gock.New("http://localhost:8000").Get("/get").
+					MatchHeader("Authorization", "^Basic dXNlcjpzZWNyZXQ=$").Reply(200)
+			},
+		},
+		{
+			name: "bearer",
+			auth: BearerToken("abc"),
+			mock: func() {
+				gock.New("http://localhost:8000").Get("/get").
+					MatchHeader("Authorization", "^Bearer abc$").Reply(200)
+			},
+		},
+		{
+			name: "api key header",
+			auth: APIKeyHeader("X-API-Key", "k3y"),
+			mock: func() {
+				gock.New("http://localhost:8000").Get("/get").
+					MatchHeader("X-API-Key", "^k3y$").Reply(200)
+			},
+		},
+		{
+			name: "api key query",
+			auth: APIKeyQuery("api_key", "k3y"),
+			mock: func() {
+				gock.New("http://localhost:8000").Get("/get").
+					MatchParam("api_key", "^k3y$").MatchParam("page", "^2$").Reply(200)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer gock.OffAll()
+			tt.mock()
+
+			c := New(Config{Host: "http://localhost:8000", Auth: tt.auth})
+			got, err := c.Get(context.Background(), "/get", struct {
+				Page int `url:"page"`
+			}{Page: 2})
+			assert.Nil(t, err)
+			assert.Equal(t, 200, got.StatusCode)
+			assert.True(t, gock.IsDone())
+		})
+	}
+}
+
+func TestRequest_WithAuth(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("http://localhost:8000").Get("/override").
+		MatchHeader("Authorization", "^Bearer other$").Reply(200)
+	gock.New("http://localhost:8000").Get("/public").Reply(200)
+	gock.New("http://localhost:8000").Get("/client").
+		MatchHeader("Authorization", "^Bearer abc$").Reply(200)
+
+	c := New(Config{Host: "http://localhost:8000", Auth: BearerToken("abc")})
+	_, err := c.WithAuth(BearerToken("other")).Get(context.Background(), "/override", nil)
+	assert.Nil(t, err)
+
+	var header []string
+	_, err = c.WithAuth(nil).WithMiddlewares(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			header = req.Header.Values("Authorization")
+			return resp, err
+		})
+	}).Get(context.Background(), "/public", nil)
+	assert.Nil(t, err)
+	assert.Empty(t, header)
+
+	_, err = c.Get(context.Background(), "/client", nil)
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	// Case authenticator error abort the request
+	errAuth := errors.New("no credentials")
+	c = New(Config{
+		Host: "http://localhost:8000",
+		Auth: AuthenticatorFunc(func(req *http.Request) error { return errAuth }),
+	})
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.ErrorIs(t, err, errAuth)
+}
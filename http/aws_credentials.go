@@ -0,0 +1,174 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials returned when a provider has no credentials configured
+var ErrNoCredentials = errors.New("aws: no credentials")
+
+// AWSCredentials used to sign requests with SigV4
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken of temporary credentials, sent as X-Amz-Security-Token
+	SessionToken string
+}
+
+// CredentialsProvider supply AWS credentials, called for every request so
+// implementations should cache & rotate credentials themselves
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+// CredentialsProviderFunc adapt ordinary function as CredentialsProvider
+type CredentialsProviderFunc func(ctx context.Context) (AWSCredentials, error)
+
+func (f CredentialsProviderFunc) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return f(ctx)
+}
+
+// StaticCredentials provide fixed credentials
+func StaticCredentials(accessKeyID, secretAccessKey, sessionToken string) CredentialsProvider {
+	creds := AWSCredentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+	}
+	return CredentialsProviderFunc(func(ctx context.Context) (AWSCredentials, error) {
+		return creds, nil
+	})
+}
+
+// EnvCredentials read AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY & AWS_SESSION_TOKEN env
+func EnvCredentials() CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (AWSCredentials, error) {
+		creds := AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return AWSCredentials{}, fmt.Errorf("%w in env", ErrNoCredentials)
+		}
+		return creds, nil
+	})
+}
+
+// ProfileCredentials read profile of shared credentials file, reloaded when the file changes.
+// Empty file means AWS_SHARED_CREDENTIALS_FILE env or ~/.aws/credentials,
+// empty profile means AWS_PROFILE env or default
+func ProfileCredentials(file, profile string) CredentialsProvider {
+	return &profileProvider{file: file, profile: profile}
+}
+
+// ChainCredentials return credentials of the first provider having them,
+// e.g. ChainCredentials(EnvCredentials(), ProfileCredentials("", ""))
+func ChainCredentials(providers ...CredentialsProvider) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (AWSCredentials, error) {
+		var errs []string
+		for _, p := range providers {
+			creds, err := p.Retrieve(ctx)
+			if err == nil {
+				return creds, nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return AWSCredentials{}, fmt.Errorf("%w: %s", ErrNoCredentials, strings.Join(errs, "; "))
+	})
+}
+
+type profileProvider struct {
+	file    string
+	profile string
+
+	mu      sync.Mutex
+	modTime time.Time
+	creds   AWSCredentials
+}
+
+func (p *profileProvider) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	file, profile := p.file, p.profile
+	if file == "" {
+		file = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, fmt.Errorf("aws: credentials file: %w", err)
+		}
+		file = filepath.Join(home, ".aws", "credentials")
+	}
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("aws: credentials file: %w", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if info.ModTime().Equal(p.modTime) && p.creds.AccessKeyID != "" {
+		return p.creds, nil
+	}
+	creds, err := readProfile(file, profile)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	p.creds, p.modTime = creds, info.ModTime()
+	return creds, nil
+}
+
+// readProfile parse aws_access_key_id, aws_secret_access_key & aws_session_token of profile
+func readProfile(file, profile string) (AWSCredentials, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("aws: credentials file: %w", err)
+	}
+	defer f.Close()
+
+	var creds AWSCredentials
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(strings.TrimPrefix(line[1:len(line)-1], "profile "))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return AWSCredentials{}, fmt.Errorf("aws: credentials file: %w", err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("%w in profile %s of %s", ErrNoCredentials, profile, file)
+	}
+	return creds, nil
+}
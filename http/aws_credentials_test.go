@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+	got, err := EnvCredentials().Retrieve(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, got)
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err = EnvCredentials().Retrieve(context.Background())
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestProfileCredentials(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "credentials")
+	assert.Nil(t, os.WriteFile(file, []byte(`
+# shared credentials
+[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+[profile partner]
+aws_access_key_id=AKIDPARTNER
+aws_secret_access_key=partner-secret
+aws_session_token=partner-token
+`), 0o600))
+
+	got, err := ProfileCredentials(file, "").Retrieve(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "AKIDDEFAULT", SecretAccessKey: "default-secret"}, got)
+
+	t.Setenv("AWS_PROFILE", "partner")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", file)
+	provider := ProfileCredentials("", "")
+	got, err = provider.Retrieve(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, AWSCredentials{
+		AccessKeyID:     "AKIDPARTNER",
+		SecretAccessKey: "partner-secret",
+		SessionToken:    "partner-token",
+	}, got)
+
+	// Case rotated file is reloaded
+	assert.Nil(t, os.WriteFile(file, []byte("[partner]\naws_access_key_id=AKIDNEW\naws_secret_access_key=new\n"), 0o600))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(file, future, future))
+	got, err = provider.Retrieve(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "AKIDNEW", got.AccessKeyID)
+
+	// Case missing profile
+	_, err = ProfileCredentials(file, "unknown").Retrieve(context.Background())
+	assert.ErrorIs(t, err, ErrNoCredentials)
+	_, err = ProfileCredentials(filepath.Join(t.TempDir(), "missing"), "").Retrieve(context.Background())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestChainCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	provider := ChainCredentials(EnvCredentials(), StaticCredentials("AKID", "secret", ""))
+	got, err := provider.Retrieve(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "AKID", got.AccessKeyID)
+
+	_, err = ChainCredentials(EnvCredentials(), ProfileCredentials(filepath.Join(t.TempDir(), "missing"), "")).
+		Retrieve(context.Background())
+	assert.ErrorIs(t, err, ErrNoCredentials)
+	assert.ErrorContains(t, err, "in env; ")
+}
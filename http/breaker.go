@@ -0,0 +1,258 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed BreakerState = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// BreakerState state of a circuit breaker
+type BreakerState int
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen returned without sending the request when the circuit is open,
+// check using errors.Is or errors.As with *CircuitOpenError
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError carry the breaker key that rejected the request
+type CircuitOpenError struct {
+	Key   string
+	State BreakerState
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCircuitOpen.Error(), e.Key)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerConfig configure circuit breaker, breakers are keyed per host
+// and optionally per endpoint template
+type BreakerConfig struct {
+	// ConsecutiveFailures to open the circuit, 0 means disabled
+	ConsecutiveFailures int
+	// FailureRate ratio (0-1] of failed requests within Window to open the circuit, 0 means disabled
+	FailureRate float64
+	// MinRequests within Window before FailureRate is evaluated
+	MinRequests int
+	// Window of failure rate counting in milliseconds
+	Window int
+	// CoolDown of open state before trying half-open in milliseconds
+	CoolDown int
+	// HalfOpenRequests max concurrent trial requests in half-open state, default 1
+	HalfOpenRequests int
+	// PerEndpoint key breaker by host & endpoint template instead of host only. Requests without
+	// path params have no template, they share the host breaker to keep the number of breakers bounded
+	PerEndpoint bool
+	// IsFailure decide whether a result counted as failure, default: transport error or 5xx.
+	// Errors caused by the caller ctx (cancelled or its deadline expired) are never counted
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange called synchronously on every state transition, must not block
+	OnStateChange func(key string, from, to BreakerState)
+}
+
+// circuitBreakers hold breakers shared by all requests of a client
+type circuitBreakers struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	breakers map[string]*breaker
+	now      func() time.Time
+}
+
+func newCircuitBreakers(cfg *BreakerConfig) *circuitBreakers {
+	if cfg == nil {
+		return nil
+	}
+	return &circuitBreakers{
+		cfg:      *cfg,
+		breakers: make(map[string]*breaker),
+		now:      time.Now,
+	}
+}
+
+// key build breaker key from host & endpoint, endpoint is only used when it is a template
+func (cb *circuitBreakers) key(host, endpoint string, template bool) string {
+	if cb != nil && cb.cfg.PerEndpoint && template {
+		return host + endpoint
+	}
+	return host
+}
+
+// allow check whether a request to key can be sent, the returned done func must be called
+// with the request result. Errors while ctx is done are caused by the caller, not the downstream,
+// so they are not recorded
+func (cb *circuitBreakers) allow(ctx context.Context, key string) (func(resp *http.Response, err error), error) {
+	if cb == nil {
+		return func(*http.Response, error) {}, nil
+	}
+	cb.mu.Lock()
+	b, ok := cb.breakers[key]
+	if !ok {
+		b = &breaker{key: key, cfg: &cb.cfg, now: cb.now}
+		cb.breakers[key] = b
+	}
+	cb.mu.Unlock()
+
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	return func(resp *http.Response, err error) {
+		if err != nil && ctx.Err() != nil {
+			b.release()
+			return
+		}
+		b.record(cb.isFailure(resp, err))
+	}, nil
+}
+
+// state return current state of breaker key
+func (cb *circuitBreakers) state(key string) BreakerState {
+	cb.mu.Lock()
+	b, ok := cb.breakers[key]
+	cb.mu.Unlock()
+	if !ok {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+func (cb *circuitBreakers) isFailure(resp *http.Response, err error) bool {
+	if cb.cfg.IsFailure != nil {
+		return cb.cfg.IsFailure(resp, err)
+	}
+	return err != nil || resp == nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+type breaker struct {
+	key string
+	cfg *BreakerConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	state       BreakerState
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	trials      int
+}
+
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return &CircuitOpenError{Key: b.key, State: b.state}
+	case StateHalfOpen:
+		limit := b.cfg.HalfOpenRequests
+		if limit <= 0 {
+			limit = 1
+		}
+		if b.trials >= limit {
+			return &CircuitOpenError{Key: b.key, State: b.state}
+		}
+		b.trials++
+	}
+	return nil
+}
+
+func (b *breaker) record(failure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		if failure {
+			b.setState(StateOpen)
+		} else {
+			b.setState(StateClosed)
+		}
+		return
+	}
+	if b.state != StateClosed {
+		return
+	}
+
+	now := b.now()
+	window := time.Duration(b.cfg.Window) * time.Millisecond
+	if window > 0 && now.Sub(b.windowStart) > window {
+		b.windowStart, b.requests, b.failures = now, 0, 0
+	}
+	b.requests++
+	if failure {
+		b.failures++
+		b.consecutive++
+	} else {
+		b.consecutive = 0
+	}
+
+	if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+		b.setState(StateOpen)
+		return
+	}
+	if b.cfg.FailureRate > 0 && b.requests >= b.cfg.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.cfg.FailureRate {
+		b.setState(StateOpen)
+	}
+}
+
+// release free half-open trial slot without recording a result
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen && b.trials > 0 {
+		b.trials--
+	}
+}
+
+// refresh move open state to half-open once cool down elapsed
+func (b *breaker) refresh() {
+	coolDown := time.Duration(b.cfg.CoolDown) * time.Millisecond
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= coolDown {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *breaker) setState(state BreakerState) {
+	from := b.state
+	if from == state {
+		return
+	}
+	b.state = state
+	b.trials = 0
+	switch state {
+	case StateOpen:
+		b.openedAt = b.now()
+	case StateClosed:
+		b.windowStart, b.requests, b.failures, b.consecutive = b.now(), 0, 0, 0
+	}
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.key, from, state)
+	}
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func Test_circuitBreakers_consecutiveFailures(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	var transitions []BreakerState
+	cb := newCircuitBreakers(&BreakerConfig{
+		ConsecutiveFailures: 2,
+		CoolDown:            1000,
+		OnStateChange: func(key string, from, to BreakerState) {
+			assert.Equal(t, "localhost", key)
+			transitions = append(transitions, to)
+		},
+	})
+	cb.now = clock.now
+
+	fail := &http.Response{StatusCode: http.StatusInternalServerError}
+	ok := &http.Response{StatusCode: http.StatusOK}
+	for i := 0; i < 2; i++ {
+		done, err := cb.allow(context.Background(), "localhost")
+		assert.Nil(t, err)
+		done(fail, nil)
+	}
+	assert.Equal(t, StateOpen, cb.state("localhost"))
+
+	_, err := cb.allow(context.Background(), "localhost")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	var openErr *CircuitOpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, "localhost", openErr.Key)
+
+	// cool down elapsed, only one trial allowed
+	clock.add(time.Second)
+	done, err := cb.allow(context.Background(), "localhost")
+	assert.Nil(t, err)
+	_, err = cb.allow(context.Background(), "localhost")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	// failed trial open the circuit again
+	done(nil, errors.New("connection reset"))
+	assert.Equal(t, StateOpen, cb.state("localhost"))
+
+	clock.add(time.Second)
+	done, err = cb.allow(context.Background(), "localhost")
+	assert.Nil(t, err)
+	done(ok, nil)
+	assert.Equal(t, StateClosed, cb.state("localhost"))
+
+	assert.Equal(t, []BreakerState{
+		StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed,
+	}, transitions)
+}
+
+func Test_circuitBreakers_failureRate(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	cb := newCircuitBreakers(&BreakerConfig{
+		FailureRate: 0.5,
+		MinRequests: 4,
+		Window:      1000,
+		CoolDown:    1000,
+	})
+	cb.now = clock.now
+
+	results := []int{200, 500, 200, 500}
+	for i, code := range results {
+		done, err := cb.allow(context.Background(), "localhost")
+		assert.Nil(t, err)
+		done(&http.Response{StatusCode: code}, nil)
+		if i < len(results)-1 {
+			assert.Equal(t, StateClosed, cb.state("localhost"))
+		}
+	}
+	assert.Equal(t, StateOpen, cb.state("localhost"))
+
+	// failures outside window are forgotten
+	clock.add(time.Second)
+	done, _ := cb.allow(context.Background(), "localhost")
+	done(&http.Response{StatusCode: 200}, nil)
+	clock.add(2 * time.Second)
+	for _, code := range []int{500, 200, 200, 200} {
+		done, err := cb.allow(context.Background(), "localhost")
+		assert.Nil(t, err)
+		done(&http.Response{StatusCode: code}, nil)
+	}
+	assert.Equal(t, StateClosed, cb.state("localhost"))
+}
+
+func Test_circuitBreakers_key(t *testing.T) {
+	var nilBreakers *circuitBreakers
+	assert.Equal(t, "localhost:8000", nilBreakers.key("localhost:8000", "/users/{id}", true))
+
+	cb := newCircuitBreakers(&BreakerConfig{})
+	assert.Equal(t, "localhost:8000", cb.key("localhost:8000", "/users/{id}", true))
+
+	cb = newCircuitBreakers(&BreakerConfig{PerEndpoint: true})
+	assert.Equal(t, "localhost:8000/users/{id}", cb.key("localhost:8000", "/users/{id}", true))
+	// raw endpoint is not a template
+	assert.Equal(t, "localhost:8000", cb.key("localhost:8000", "/users/1", false))
+}
+
+func Test_requestImpl_exec_breaker(t *testing.T) {
+	t.Run("reject without sending when circuit open", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/users/1").Reply(503)
+		gock.New("http://localhost:8000").Get("/users/2").Reply(200)
+
+		c := &clientImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+			breakers: newCircuitBreakers(&BreakerConfig{
+				ConsecutiveFailures: 1,
+				CoolDown:            60000,
+				PerEndpoint:         true,
+			}),
+		}
+		got, err := c.WithPathParams(map[string]string{"id": "1"}).Get(context.Background(), "/users/{id}", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 503, got.StatusCode)
+
+		got, err = c.WithPathParams(map[string]string{"id": "2"}).Get(context.Background(), "/users/{id}", nil)
+		assert.Nil(t, got)
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+		assert.False(t, gock.IsDone())
+
+		// endpoint without path params use host breaker
+		gock.New("http://localhost:8000").Get("/users/3").Reply(503)
+		gock.New("http://localhost:8000").Get("/users/4").Reply(200)
+		got, err = c.Get(context.Background(), "/users/3", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 503, got.StatusCode)
+		got, err = c.Get(context.Background(), "/users/4", nil)
+		assert.Nil(t, got)
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+		assert.Equal(t, "localhost:8000", err.(*CircuitOpenError).Key)
+	})
+	t.Run("caller cancellation is not a failure", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				select {
+				case <-time.After(100 * time.Millisecond):
+				case <-r.Context().Done():
+				}
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		c := New(Config{
+			Host:    srv.URL,
+			Breaker: &BreakerConfig{ConsecutiveFailures: 2, CoolDown: 60000},
+		})
+		for i := 0; i < 2; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			_, err := c.Get(ctx, "/slow", nil)
+			cancel()
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		}
+
+		got, err := c.Get(context.Background(), "/health", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+	})
+}
+
+func Test_circuitBreakers_halfOpenRelease(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	cb := newCircuitBreakers(&BreakerConfig{ConsecutiveFailures: 1, CoolDown: 1000})
+	cb.now = clock.now
+
+	done, err := cb.allow(context.Background(), "localhost")
+	assert.Nil(t, err)
+	done(nil, errors.New("connection refused"))
+	assert.Equal(t, StateOpen, cb.state("localhost"))
+	clock.add(time.Second)
+
+	// cancelled trial free its slot & keep half-open
+	ctx, cancel := context.WithCancel(context.Background())
+	done, err = cb.allow(ctx, "localhost")
+	assert.Nil(t, err)
+	cancel()
+	done(nil, context.Canceled)
+	assert.Equal(t, StateHalfOpen, cb.state("localhost"))
+
+	done, err = cb.allow(context.Background(), "localhost")
+	assert.Nil(t, err)
+	done(&http.Response{StatusCode: 200}, nil)
+	assert.Equal(t, StateClosed, cb.state("localhost"))
+}
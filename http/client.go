@@ -2,14 +2,23 @@ package http
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"time"
+
+	"github.com/cymon1997/go-client/http/codec"
 )
 
 type Client interface {
 	SetBaseHeaders(headers map[string]string)
+	Use(middlewares ...Middleware)
 	WithHeaders(headers map[string]string) Request
 	WithCookies(cookies []*http.Cookie) Request
+	WithRetry(policy *RetryPolicy) Request
+	WithPathParams(params map[string]string) Request
+	WithMiddlewares(middlewares ...Middleware) Request
+	WithAuth(auth Authenticator) Request
+	WithCodec(c codec.Codec) Request
 
 	Get(ctx context.Context, endpoint string, params interface{}) (*http.Response, error)
 	Post(ctx context.Context, endpoint string, body interface{}) (*http.Response, error)
@@ -18,64 +27,160 @@ type Client interface {
 	PutRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error)
 	Patch(ctx context.Context, endpoint string, body interface{}) (*http.Response, error)
 	PatchRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error)
+	PostStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error)
+	PutStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error)
+	PatchStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error)
 	Delete(ctx context.Context, endpoint string) (*http.Response, error)
+
+	Subscribe(ctx context.Context, endpoint string, handler func(Event) error) error
+	Events(ctx context.Context, endpoint string) (<-chan Event, <-chan error)
+	Dial(ctx context.Context, endpoint string) (*WebSocket, error)
 }
 
 type clientImpl struct {
 	client      *http.Client
 	baseURL     string
 	baseHeaders map[string]string
+	retry       *RetryPolicy
+	breakers    *circuitBreakers
+	limiter     *rateLimiter
+	middlewares []Middleware
+	httpError   bool
+	logger      *requestLogger
+	auth        Authenticator
+	codec       codec.Codec
+	websocket   *WebSocketConfig
 }
 
-func New(cfg Config) Client {
-	return &clientImpl{
+func New(cfg Config, opts ...Option) Client {
+	c := &clientImpl{
 		client: &http.Client{
-			Timeout: time.Duration(cfg.Timeout) * time.Millisecond,
+			Timeout:   time.Duration(cfg.Timeout) * time.Millisecond,
+			Transport: newTransport(cfg),
 		},
-		baseURL: cfg.Host,
+		baseURL:   cfg.Host,
+		retry:     cfg.Retry,
+		breakers:  newCircuitBreakers(cfg.Breaker),
+		limiter:   newRateLimiter(cfg.RateLimit),
+		httpError: cfg.ReturnHTTPError,
+		logger:    newRequestLogger(cfg.Log),
+		auth:      cfg.Auth,
+		codec:     cfg.Codec,
+		websocket: cfg.WebSocket,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *clientImpl) SetBaseHeaders(headers map[string]string) {
 	c.baseHeaders = headers
 }
 
+// Use register middlewares applied to all requests, see Middleware for ordering
+func (c *clientImpl) Use(middlewares ...Middleware) {
+	c.middlewares = appendMiddlewares(c.middlewares, middlewares...)
+}
+
 func (c *clientImpl) WithHeaders(headers map[string]string) Request {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).WithHeaders(headers)
+	return c.newRequest().WithHeaders(headers)
 }
 
 func (c *clientImpl) WithCookies(cookies []*http.Cookie) Request {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).WithCookies(cookies)
+	return c.newRequest().WithCookies(cookies)
+}
+
+func (c *clientImpl) WithRetry(policy *RetryPolicy) Request {
+	return c.newRequest().WithRetry(policy)
+}
+
+func (c *clientImpl) WithPathParams(params map[string]string) Request {
+	return c.newRequest().WithPathParams(params)
+}
+
+func (c *clientImpl) WithMiddlewares(middlewares ...Middleware) Request {
+	return c.newRequest().WithMiddlewares(middlewares...)
+}
+
+func (c *clientImpl) WithAuth(auth Authenticator) Request {
+	return c.newRequest().WithAuth(auth)
+}
+
+func (c *clientImpl) WithCodec(cd codec.Codec) Request {
+	return c.newRequest().WithCodec(cd)
 }
 
 func (c *clientImpl) Get(ctx context.Context, endpoint string, params interface{}) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).Get(ctx, endpoint, params)
+	return c.newRequest().Get(ctx, endpoint, params)
 }
 
 func (c *clientImpl) Post(ctx context.Context, endpoint string, body interface{}) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).Post(ctx, endpoint, body)
+	return c.newRequest().Post(ctx, endpoint, body)
 }
 
 func (c *clientImpl) PostRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).PostRaw(ctx, endpoint, raw)
+	return c.newRequest().PostRaw(ctx, endpoint, raw)
 }
 
 func (c *clientImpl) Put(ctx context.Context, endpoint string, body interface{}) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).Put(ctx, endpoint, body)
+	return c.newRequest().Put(ctx, endpoint, body)
 }
 
 func (c *clientImpl) PutRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).PutRaw(ctx, endpoint, raw)
+	return c.newRequest().PutRaw(ctx, endpoint, raw)
 }
 
 func (c *clientImpl) Patch(ctx context.Context, endpoint string, body interface{}) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).Patch(ctx, endpoint, body)
+	return c.newRequest().Patch(ctx, endpoint, body)
 }
 
 func (c *clientImpl) PatchRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).PatchRaw(ctx, endpoint, raw)
+	return c.newRequest().PatchRaw(ctx, endpoint, raw)
+}
+
+func (c *clientImpl) PostStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	return c.newRequest().PostStream(ctx, endpoint, body, size)
+}
+
+func (c *clientImpl) PutStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	return c.newRequest().PutStream(ctx, endpoint, body, size)
+}
+
+func (c *clientImpl) PatchStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	return c.newRequest().PatchStream(ctx, endpoint, body, size)
 }
 
 func (c *clientImpl) Delete(ctx context.Context, endpoint string) (*http.Response, error) {
-	return NewRequest(c.client, c.baseURL, c.baseHeaders).Delete(ctx, endpoint)
+	return c.newRequest().Delete(ctx, endpoint)
+}
+
+func (c *clientImpl) Subscribe(ctx context.Context, endpoint string, handler func(Event) error) error {
+	return c.newRequest().Subscribe(ctx, endpoint, handler)
+}
+
+func (c *clientImpl) Events(ctx context.Context, endpoint string) (<-chan Event, <-chan error) {
+	return c.newRequest().Events(ctx, endpoint)
+}
+
+func (c *clientImpl) Dial(ctx context.Context, endpoint string) (*WebSocket, error) {
+	return c.newRequest().Dial(ctx, endpoint)
+}
+
+// newRequest create request inheriting client settings
+func (c *clientImpl) newRequest() *requestImpl {
+	return &requestImpl{
+		client:      c.client,
+		baseURL:     c.baseURL,
+		headers:     c.baseHeaders,
+		retry:       c.retry,
+		breakers:    c.breakers,
+		limiter:     c.limiter,
+		middlewares: c.middlewares,
+		httpError:   c.httpError,
+		logger:      c.logger,
+		auth:        c.auth,
+		codec:       c.codec,
+		websocket:   c.websocket,
+	}
 }
@@ -0,0 +1,107 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/go-querystring/query"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	// JSON codec using encoding/json
+	JSON Codec = jsonCodec{}
+	// XML codec using encoding/xml
+	XML Codec = xmlCodec{}
+	// Form codec for application/x-www-form-urlencoded, see formCodec for supported types
+	Form Codec = formCodec{}
+	// YAML codec using go.yaml.in/yaml/v3
+	YAML Codec = yamlCodec{}
+	// CBOR codec using github.com/fxamacker/cbor/v2
+	CBOR Codec = cborCodec{}
+	// MessagePack codec using github.com/vmihailenco/msgpack/v5
+	MessagePack Codec = msgpackCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                        { return "application/json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                        { return "application/xml" }
+func (xmlCodec) Marshal(v interface{}) ([]byte, error)      { return xml.Marshal(v) }
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error { return xml.Unmarshal(data, v) }
+
+type yamlCodec struct{}
+
+func (yamlCodec) ContentType() string                        { return "application/yaml" }
+func (yamlCodec) Marshal(v interface{}) ([]byte, error)      { return yaml.Marshal(v) }
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error { return yaml.Unmarshal(data, v) }
+
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string                        { return "application/cbor" }
+func (cborCodec) Marshal(v interface{}) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v interface{}) error { return cbor.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string                        { return "application/msgpack" }
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+// formCodec encode url.Values, map[string]string or struct with `url` tags,
+// decode into *url.Values, *map[string][]string or *map[string]string
+type formCodec struct{}
+
+func (formCodec) ContentType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+func (formCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case url.Values:
+		return []byte(v.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(v).Encode()), nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for k, val := range v {
+			values.Set(k, val)
+		}
+		return []byte(values.Encode()), nil
+	}
+	values, err := query.Values(v)
+	if err != nil {
+		return nil, fmt.Errorf("codec: form: %w", err)
+	}
+	return []byte(values.Encode()), nil
+}
+
+func (formCodec) Unmarshal(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return fmt.Errorf("codec: form: %w", err)
+	}
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+	case *map[string][]string:
+		*v = values
+	case *map[string]string:
+		*v = make(map[string]string, len(values))
+		for k := range values {
+			(*v)[k] = values.Get(k)
+		}
+	default:
+		return fmt.Errorf("codec: form: unsupported type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Package codec provide encoding of request & response bodies selected by media type
+package codec
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Codec encode & decode bodies of one media type
+type Codec interface {
+	// ContentType sent as Content-Type of encoded bodies & Accept of requests
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Registry map media types to codecs, safe for concurrent use
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewRegistry create registry of codecs, each registered by its content type
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{codecs: make(map[string]Codec)}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// Register codec for its content type & additional media types, replacing existing ones
+func (r *Registry) Register(c Codec, mediaTypes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range append([]string{c.ContentType()}, mediaTypes...) {
+		r.codecs[mediaType(t)] = c
+	}
+}
+
+// Lookup find codec of Content-Type header value, parameters are ignored
+// and structured syntax suffixes fall back to their base type, e.g. application/problem+json
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	t := mediaType(contentType)
+	if t == "" {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.codecs[t]; ok {
+		return c, true
+	}
+	if i := strings.LastIndexByte(t, '+'); i >= 0 {
+		c, ok := r.codecs["application/"+t[i+1:]]
+		return c, ok
+	}
+	return nil, false
+}
+
+// Default registry holding all built-in codecs
+var Default = NewRegistry(JSON, XML, Form, YAML, CBOR, MessagePack)
+
+// Register codec in Default registry
+func Register(c Codec, mediaTypes ...string) {
+	Default.Register(c, mediaTypes...)
+}
+
+// Lookup find codec of Content-Type in Default registry
+func Lookup(contentType string) (Codec, bool) {
+	return Default.Lookup(contentType)
+}
+
+// mediaType return lower-cased media type without parameters
+func mediaType(contentType string) string {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// ForResponse return codec of response Content-Type, falling back to
+// codec of Accept header of the request, then JSON
+func ForResponse(resp *http.Response) Codec {
+	if c, ok := Lookup(resp.Header.Get("Content-Type")); ok {
+		return c
+	}
+	if resp.Request != nil {
+		if c, ok := Lookup(resp.Request.Header.Get("Accept")); ok {
+			return c
+		}
+	}
+	return JSON
+}
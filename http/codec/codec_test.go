@@ -0,0 +1,105 @@
+package codec
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Name  string `json:"name" xml:"name" yaml:"name" cbor:"name" msgpack:"name" url:"name"`
+	Count int    `json:"count" xml:"count" yaml:"count" cbor:"count" msgpack:"count" url:"count"`
+}
+
+func TestCodec_roundTrip(t *testing.T) {
+	tests := []struct {
+		codec   Codec
+		encoded string
+	}{
+		{codec: JSON, encoded: `{"name":"go","count":2}`},
+		{codec: XML, encoded: `<sample><name>go</name><count>2</count></sample>`},
+		{codec: YAML, encoded: "name: go\ncount: 2\n"},
+		{codec: CBOR},
+		{codec: MessagePack},
+	}
+	for _, tt := range tests {
+		t.Run(tt.codec.ContentType(), func(t *testing.T) {
+			raw, err := tt.codec.Marshal(sample{Name: "go", Count: 2})
+			assert.Nil(t, err)
+			if tt.encoded != "" {
+				assert.Equal(t, tt.encoded, string(raw))
+			}
+			var got sample
+			assert.Nil(t, tt.codec.Unmarshal(raw, &got))
+			assert.Equal(t, sample{Name: "go", Count: 2}, got)
+		})
+	}
+}
+
+func TestForm(t *testing.T) {
+	for _, v := range []interface{}{
+		sample{Name: "go", Count: 2},
+		map[string]string{"name": "go", "count": "2"},
+		url.Values{"name": {"go"}, "count": {"2"}},
+	} {
+		raw, err := Form.Marshal(v)
+		assert.Nil(t, err)
+		assert.Equal(t, "count=2&name=go", string(raw))
+	}
+
+	var values url.Values
+	assert.Nil(t, Form.Unmarshal([]byte("name=go&tag=a&tag=b"), &values))
+	assert.Equal(t, url.Values{"name": {"go"}, "tag": {"a", "b"}}, values)
+	var m map[string]string
+	assert.Nil(t, Form.Unmarshal([]byte("name=go&tag=a&tag=b"), &m))
+	assert.Equal(t, map[string]string{"name": "go", "tag": "a"}, m)
+	assert.Error(t, Form.Unmarshal([]byte("name=go"), &sample{}))
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Codec
+	}{
+		{contentType: "application/json", want: JSON},
+		{contentType: "Application/JSON; charset=utf-8", want: JSON},
+		{contentType: "application/problem+json", want: JSON},
+		{contentType: "application/atom+xml", want: XML},
+		{contentType: "application/x-www-form-urlencoded", want: Form},
+		{contentType: "application/cbor", want: CBOR},
+		{contentType: "application/msgpack", want: MessagePack},
+		{contentType: "text/plain"},
+		{contentType: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, ok := Lookup(tt.contentType)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Case aliases
+	r := NewRegistry(JSON)
+	r.Register(YAML, "text/yaml", "application/x-yaml")
+	got, ok := r.Lookup("text/yaml")
+	assert.True(t, ok)
+	assert.Equal(t, YAML, got)
+	_, ok = r.Lookup("application/xml")
+	assert.False(t, ok)
+}
+
+func TestForResponse(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	resp := &http.Response{Header: http.Header{"Content-Type": {"application/yaml"}}, Request: req}
+	assert.Equal(t, YAML, ForResponse(resp))
+
+	resp.Header = http.Header{}
+	assert.Equal(t, XML, ForResponse(resp))
+
+	resp.Request = nil
+	assert.Equal(t, JSON, ForResponse(resp))
+}
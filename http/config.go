@@ -1,8 +1,34 @@
 package http
 
+import (
+	"github.com/cymon1997/go-client/http/codec"
+)
+
 type Config struct {
 	// Host including http protocol
 	Host string
 	// Timeout in milliseconds
 	Timeout int
+	// Transport connection pooling & timeouts, zero value means http.DefaultTransport
+	Transport TransportConfig
+	// TLS custom CA, client certificate & TLS parameters, nil means system defaults
+	TLS *TLSConfig
+	// Proxy of outgoing requests, nil means HTTP_PROXY, HTTPS_PROXY & NO_PROXY env
+	Proxy *ProxyConfig
+	// Auth authenticator invoked for every request, nil means no authentication
+	Auth Authenticator
+	// Codec encoding request bodies, also sent as Accept, nil means codec.JSON
+	Codec codec.Codec
+	// WebSocket keepalive & reconnection of connections opened by Dial, nil means defaults
+	WebSocket *WebSocketConfig
+	// Retry policy applied to all requests, nil means no retry
+	Retry *RetryPolicy
+	// Breaker circuit breaker shared by all requests, nil means disabled
+	Breaker *BreakerConfig
+	// RateLimit client-side rate limiting shared by all requests, nil means unlimited
+	RateLimit *RateLimitConfig
+	// ReturnHTTPError return *HTTPError alongside the response for non-2xx status
+	ReturnHTTPError bool
+	// Log request & response logging, nil means no logging
+	Log *LogConfig
 }
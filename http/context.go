@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type requestInfoKey struct{}
+
+// requestInfo describe the attempt being sent, available to middlewares via request context
+type requestInfo struct {
+	endpoint   string
+	attempt    int
+	pathParams bool
+}
+
+func withRequestInfo(ctx context.Context, endpoint string, attempt int, pathParams bool) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, requestInfo{
+		endpoint:   endpoint,
+		attempt:    attempt,
+		pathParams: pathParams,
+	})
+}
+
+// EndpointTemplate return unexpanded endpoint of the request, e.g. /users/{id},
+// only available within request context seen by middlewares
+func EndpointTemplate(ctx context.Context) string {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info.endpoint
+}
+
+// HasPathParams report whether path params were set using Request.WithPathParams,
+// otherwise EndpointTemplate is the raw endpoint which may contain IDs.
+// Only available within request context seen by middlewares
+func HasPathParams(ctx context.Context) bool {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info.pathParams
+}
+
+// afterFunc call f in its own goroutine once ctx is done unless stop is called first,
+// like context.AfterFunc which is only available since Go 1.21. Either f or stop win,
+// stop called while f is running wait for it
+func afterFunc(ctx context.Context, f func()) (stop func()) {
+	stopped := make(chan struct{})
+	var once sync.Once
+	go func() {
+		select {
+		case <-ctx.Done():
+			once.Do(f)
+		case <-stopped:
+		}
+	}()
+	return func() { once.Do(func() { close(stopped) }) }
+}
+
+// withoutCancel keep values of parent but never done, like context.WithoutCancel
+// which is only available since Go 1.21
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (withoutCancel) Done() <-chan struct{}       { return nil }
+func (withoutCancel) Err() error                  { return nil }
+
+// Attempt return attempt number of the request starting from 1,
+// only available within request context seen by middlewares, 0 otherwise
+func Attempt(ctx context.Context) int {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info.attempt
+}
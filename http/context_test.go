@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_afterFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	called := make(chan struct{})
+	afterFunc(ctx, func() { close(called) })
+	cancel()
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("f not called after ctx is done")
+	}
+
+	// Case stopped before ctx is done
+	ctx, cancel = context.WithCancel(context.Background())
+	var calledAfterStop bool
+	stop := afterFunc(ctx, func() { calledAfterStop = true })
+	stop()
+	stop()
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, calledAfterStop)
+}
+
+func Test_withoutCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), requestInfoKey{}, requestInfo{attempt: 2}), time.Millisecond)
+	cancel()
+	detached := withoutCancel{ctx}
+	assert.Nil(t, detached.Err())
+	assert.Nil(t, detached.Done())
+	_, ok := detached.Deadline()
+	assert.False(t, ok)
+	assert.Equal(t, 2, Attempt(detached))
+}
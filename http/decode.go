@@ -0,0 +1,37 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/cymon1997/go-client/http/codec"
+	"github.com/cymon1997/go-client/http/util"
+)
+
+// DecodeResponse check status & decode 2xx response body into T using codec of
+// response Content-Type (see codec.ForResponse), body is always closed. Non-2xx
+// responses return *HTTPError, e.g.
+//
+//	order, resp, err := DecodeResponse[Order](c.WithCodec(codec.XML).Post(ctx, "/orders", order))
+func DecodeResponse[T any](resp *http.Response, err error) (T, *http.Response, error) {
+	var res T
+	if err != nil {
+		return res, resp, err
+	}
+	defer resp.Body.Close()
+
+	if !util.IsStatusOK(resp) {
+		httpErr, err := NewHTTPError(resp)
+		if err != nil {
+			return res, resp, err
+		}
+		return res, resp, httpErr
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil || len(raw) == 0 {
+		return res, resp, err
+	}
+	err = codec.ForResponse(resp).Unmarshal(raw, &res)
+	return res, resp, err
+}
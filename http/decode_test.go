@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cymon1997/go-client/http/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type codecData struct {
+	ID   int    `xml:"id" yaml:"id"`
+	Name string `xml:"name" yaml:"name"`
+}
+
+func TestRequest_WithCodec(t *testing.T) {
+	var received *http.Request
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		received, body = r, string(raw)
+		switch r.URL.Path {
+		case "/xml":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			_, _ = w.Write([]byte(`<codecData><id>2</id><name>created</name></codecData>`))
+		case "/yaml":
+			w.Header()["Content-Type"] = nil
+			_, _ = w.Write([]byte("id: 3\nname: listed\n"))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(Config{Host: srv.URL})
+	got, _, err := DecodeResponse[codecData](c.WithCodec(codec.XML).
+		Post(context.Background(), "/xml", codecData{ID: 1, Name: "go"}))
+	assert.Nil(t, err)
+	assert.Equal(t, codecData{ID: 2, Name: "created"}, got)
+	assert.Equal(t, `<codecData><id>1</id><name>go</name></codecData>`, body)
+	assert.Equal(t, "application/xml", received.Header.Get("Content-Type"))
+	assert.Equal(t, "application/xml", received.Header.Get("Accept"))
+
+	// Case response without Content-Type decoded using codec sent as Accept
+	c = New(Config{Host: srv.URL, Codec: codec.YAML})
+	got, _, err = DecodeResponse[codecData](c.Get(context.Background(), "/yaml", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, codecData{ID: 3, Name: "listed"}, got)
+	assert.Equal(t, "application/yaml", received.Header.Get("Accept"))
+
+	// Case headers override codec & raw body has no Content-Type
+	_, err = c.WithHeaders(map[string]string{"Accept": "text/plain"}).
+		PostRaw(context.Background(), "/raw", []byte("raw"))
+	assert.Nil(t, err)
+	assert.Empty(t, received.Header.Get("Content-Type"))
+	assert.Equal(t, "text/plain", received.Header.Get("Accept"))
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxHTTPErrorBody limit of response body copied into HTTPError
+const maxHTTPErrorBody = 64 << 10
+
+// HTTPError describe a non-2xx response, returned by typed helpers
+// and by Client methods when Config.ReturnHTTPError is enabled
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	// Body copy of response body, at most 64KB
+	Body []byte
+	// Truncated whether Body is cut at the limit
+	Truncated bool
+	// Payload response body decoded as JSON, nil when body is not valid JSON
+	Payload interface{}
+}
+
+// NewHTTPError build HTTPError from resp, the body is consumed & closed,
+// then replaced with the bounded copy so it can still be read by the caller
+func NewHTTPError(resp *http.Response) (*HTTPError, error) {
+	e := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.URL = resp.Request.URL.String()
+	}
+	if resp.Body == nil {
+		return e, nil
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPErrorBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxHTTPErrorBody {
+		raw, e.Truncated = raw[:maxHTTPErrorBody], true
+	}
+	e.Body = raw
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if !e.Truncated {
+		_ = json.Unmarshal(raw, &e.Payload)
+	}
+	return e, nil
+}
+
+func (e *HTTPError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("http: unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("http: %s %s: unexpected status %d %s",
+		e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Decode response body into dest, e.g. service specific error struct
+func (e *HTTPError) Decode(dest interface{}) error {
+	return json.Unmarshal(e.Body, dest)
+}
+
+// IsBadRequest check whether err is HTTPError with status 400
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+// IsUnauthorized check whether err is HTTPError with status 401
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden check whether err is HTTPError with status 403
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound check whether err is HTTPError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict check whether err is HTTPError with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsRetryable check whether err is HTTPError with status worth retrying,
+// i.e. 408 or one of DefaultRetryStatusCodes
+func IsRetryable(err error) bool {
+	var e *HTTPError
+	if !errors.As(err, &e) {
+		return false
+	}
+	if e.StatusCode == http.StatusRequestTimeout {
+		return true
+	}
+	for _, code := range DefaultRetryStatusCodes {
+		if code == e.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStatus(err error, code int) bool {
+	var e *HTTPError
+	return errors.As(err, &e) && e.StatusCode == code
+}
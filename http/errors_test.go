@@ -0,0 +1,175 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestNewHTTPError(t *testing.T) {
+	t.Run("http.NewHTTPError", func(t *testing.T) {
+		defer gock.OffAll()
+
+		client := http.Client{}
+		request, _ := http.NewRequest(
+			http.MethodGet, "http://localhost:8000/get", nil)
+
+		// Case JSON body
+		gock.New("http://localhost:8000").
+			Get("/get").
+			Reply(409).
+			SetHeader("X-Request-Id", "request_id").
+			JSON(map[string]string{"message": "conflict"})
+
+		resp, err := client.Do(request)
+		assert.Nil(t, err)
+		got, err := NewHTTPError(resp)
+		assert.Nil(t, err)
+		assert.Equal(t, http.MethodGet, got.Method)
+		assert.Equal(t, "http://localhost:8000/get", got.URL)
+		assert.Equal(t, 409, got.StatusCode)
+		assert.Equal(t, "request_id", got.Header.Get("X-Request-Id"))
+		assert.Equal(t, map[string]interface{}{"message": "conflict"}, got.Payload)
+		assert.False(t, got.Truncated)
+		assert.Equal(t, "http: GET http://localhost:8000/get: unexpected status 409 Conflict", got.Error())
+
+		// body still readable by caller
+		raw, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, got.Body, raw)
+
+		// Case body over limit
+		gock.New("http://localhost:8000").
+			Get("/get").
+			Reply(500).
+			Body(bytes.NewReader(bytes.Repeat([]byte("a"), maxHTTPErrorBody+10)))
+
+		resp, err = client.Do(request)
+		assert.Nil(t, err)
+		got, err = NewHTTPError(resp)
+		assert.Nil(t, err)
+		assert.True(t, got.Truncated)
+		assert.Len(t, got.Body, maxHTTPErrorBody)
+		assert.Nil(t, got.Payload)
+	})
+}
+
+func TestIsStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		fn   func(err error) bool
+		want bool
+	}{
+		{
+			name: "case bad request",
+			err:  &HTTPError{StatusCode: http.StatusBadRequest},
+			fn:   IsBadRequest,
+			want: true,
+		},
+		{
+			name: "case unauthorized",
+			err:  &HTTPError{StatusCode: http.StatusUnauthorized},
+			fn:   IsUnauthorized,
+			want: true,
+		},
+		{
+			name: "case forbidden",
+			err:  &HTTPError{StatusCode: http.StatusForbidden},
+			fn:   IsForbidden,
+			want: true,
+		},
+		{
+			name: "case not found wrapped",
+			err:  fmt.Errorf("get user: %w", &HTTPError{StatusCode: http.StatusNotFound}),
+			fn:   IsNotFound,
+			want: true,
+		},
+		{
+			name: "case conflict",
+			err:  &HTTPError{StatusCode: http.StatusConflict},
+			fn:   IsConflict,
+			want: true,
+		},
+		{
+			name: "case retryable request timeout",
+			err:  &HTTPError{StatusCode: http.StatusRequestTimeout},
+			fn:   IsRetryable,
+			want: true,
+		},
+		{
+			name: "case retryable service unavailable",
+			err:  &HTTPError{StatusCode: http.StatusServiceUnavailable},
+			fn:   IsRetryable,
+			want: true,
+		},
+		{
+			name: "case not retryable",
+			err:  &HTTPError{StatusCode: http.StatusNotImplemented},
+			fn:   IsRetryable,
+			want: false,
+		},
+		{
+			name: "case other status",
+			err:  &HTTPError{StatusCode: http.StatusConflict},
+			fn:   IsNotFound,
+			want: false,
+		},
+		{
+			name: "case other error",
+			err:  errors.New("other"),
+			fn:   IsNotFound,
+			want: false,
+		},
+		{
+			name: "case nil",
+			err:  nil,
+			fn:   IsRetryable,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.fn(tt.err))
+		})
+	}
+}
+
+func Test_clientImpl_ReturnHTTPError(t *testing.T) {
+	t.Run("client.ReturnHTTPError", func(t *testing.T) {
+		defer gock.OffAll()
+
+		c := New(Config{
+			Host:            "http://localhost:8000",
+			Timeout:         3000,
+			ReturnHTTPError: true,
+		})
+
+		gock.New("http://localhost:8000").
+			Delete("/delete/1").
+			Reply(404).
+			BodyString("not found")
+
+		got, err := c.Delete(context.Background(), "/delete/1")
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, 404, got.StatusCode)
+		raw, _ := io.ReadAll(got.Body)
+		assert.Equal(t, "not found", strings.TrimSpace(string(raw)))
+
+		gock.New("http://localhost:8000").
+			Delete("/delete/1").
+			Reply(200)
+
+		got, err = c.Delete(context.Background(), "/delete/1")
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+	})
+}
@@ -0,0 +1,221 @@
+// Package graphql provide GraphQL client on top of http.Client
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/cymon1997/go-client/http/codec"
+	"github.com/cymon1997/go-client/http/util"
+)
+
+// errPersistedQueryNotFound is error message & code returned by servers not knowing the APQ hash
+const (
+	errPersistedQueryNotFound     = "PersistedQueryNotFound"
+	codePersistedQueryNotFound    = "PERSISTED_QUERY_NOT_FOUND"
+	persistedQueryProtocolVersion = 1
+)
+
+// Client send GraphQL operations to a single endpoint, e.g. /graphql
+type Client struct {
+	client    httpClient.Client
+	endpoint  string
+	persisted bool
+}
+
+// Option configure Client
+type Option func(c *Client)
+
+// WithPersistedQueries enable automatic persisted queries (APQ): only the SHA-256 hash of the query
+// is sent, the full query is sent once when server does not know the hash yet
+func WithPersistedQueries() Option {
+	return func(c *Client) {
+		c.persisted = true
+	}
+}
+
+// New create GraphQL client sending operations to endpoint using c,
+// headers, auth, retry etc. of c apply to every operation
+func New(c httpClient.Client, endpoint string, opts ...Option) *Client {
+	gc := &Client{client: c, endpoint: endpoint}
+	for _, opt := range opts {
+		opt(gc)
+	}
+	return gc
+}
+
+// Query send query with variables & decode data into T. When server return errors
+// alongside partial data, both decoded T & Errors are returned
+func Query[T, V any](ctx context.Context, c *Client, query string, variables V) (T, error) {
+	return do[T](ctx, c, query, variables)
+}
+
+// Mutate send mutation with variables & decode data into T, see Query
+func Mutate[T, V any](ctx context.Context, c *Client, mutation string, variables V) (T, error) {
+	return do[T](ctx, c, mutation, variables)
+}
+
+type request struct {
+	Query      string      `json:"query,omitempty"`
+	Variables  interface{} `json:"variables,omitempty"`
+	Extensions *extensions `json:"extensions,omitempty"`
+}
+
+type extensions struct {
+	PersistedQuery persistedQuery `json:"persistedQuery"`
+}
+
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+func do[T any](ctx context.Context, c *Client, query string, variables interface{}) (T, error) {
+	var res T
+	req := request{Query: query, Variables: variables}
+	if c.persisted {
+		sum := sha256.Sum256([]byte(query))
+		req.Query = ""
+		req.Extensions = &extensions{PersistedQuery: persistedQuery{
+			Version:    persistedQueryProtocolVersion,
+			Sha256Hash: hex.EncodeToString(sum[:]),
+		}}
+	}
+
+	resp, err := c.send(ctx, req)
+	if err == nil && c.persisted && resp.Errors.persistedQueryNotFound() {
+		req.Query = query
+		resp, err = c.send(ctx, req)
+	}
+	if err != nil {
+		return res, err
+	}
+	if len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, &res); err != nil {
+			return res, err
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return res, resp.Errors
+	}
+	return res, nil
+}
+
+// send post req, non-2xx response without GraphQL errors return *http.HTTPError
+func (c *Client) send(ctx context.Context, req request) (*response, error) {
+	resp, err := c.client.WithCodec(codec.JSON).Post(ctx, c.endpoint, req)
+	var httpErr *httpClient.HTTPError
+	if errors.As(err, &httpErr) {
+		return errorResponse(httpErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !util.IsStatusOK(resp) {
+		httpErr, err := httpClient.NewHTTPError(resp)
+		if err != nil {
+			return nil, err
+		}
+		return errorResponse(httpErr)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var res response
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// errorResponse use GraphQL errors carried by non-2xx response body, if any
+func errorResponse(httpErr *httpClient.HTTPError) (*response, error) {
+	var res response
+	if json.Unmarshal(httpErr.Body, &res) == nil && len(res.Errors) > 0 {
+		return &res, nil
+	}
+	return nil, httpErr
+}
+
+// Location of error in query
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a GraphQL error returned in errors of response
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path to the field causing error, elements are field names (string) or list indexes (float64)
+	Path []interface{} `json:"path,omitempty"`
+	// Extensions server specific details, e.g. code
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Path) == 0 {
+		return "graphql: " + e.Message
+	}
+	path := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		b, _ := json.Marshal(p)
+		path[i] = strings.Trim(string(b), `"`)
+	}
+	return "graphql: " + e.Message + " (path: " + strings.Join(path, ".") + ")"
+}
+
+// Code return extensions.code, empty when not set
+func (e *Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Errors returned by Query & Mutate when response contains errors,
+// use errors.As with *Error to inspect the first one
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (e Errors) persistedQueryNotFound() bool {
+	for _, err := range e {
+		if err.Message == errPersistedQueryNotFound || err.Code() == codePersistedQueryNotFound {
+			return true
+		}
+	}
+	return false
+}
+
+// IsErrors check whether err contains GraphQL errors
+func IsErrors(err error) bool {
+	var e Errors
+	return errors.As(err, &e)
+}
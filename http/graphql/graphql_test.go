@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/stretchr/testify/assert"
+)
+
+type userVars struct {
+	ID string `json:"id"`
+}
+
+type userResult struct {
+	User struct {
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+type gqlRequest struct {
+	Query      string                 `json:"query"`
+	Variables  map[string]interface{} `json:"variables"`
+	Extensions *extensions            `json:"extensions"`
+}
+
+func newServer(t *testing.T, handler func(req gqlRequest) (int, string)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var req gqlRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		status, body := handler(req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestQuery(t *testing.T) {
+	const query = `query User($id: ID!) { user(id: $id) { name } }`
+
+	t.Run("data", func(t *testing.T) {
+		srv := newServer(t, func(req gqlRequest) (int, string) {
+			assert.Equal(t, query, req.Query)
+			assert.Equal(t, map[string]interface{}{"id": "1"}, req.Variables)
+			assert.Nil(t, req.Extensions)
+			return 200, `{"data":{"user":{"name":"alice"}}}`
+		})
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/graphql")
+
+		got, err := Query[userResult](context.Background(), c, query, userVars{ID: "1"})
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", got.User.Name)
+	})
+
+	t.Run("partial data with errors", func(t *testing.T) {
+		srv := newServer(t, func(req gqlRequest) (int, string) {
+			return 200, `{"data":{"user":{"name":"alice"}},"errors":[{"message":"forbidden",` +
+				`"locations":[{"line":1,"column":30}],"path":["user","friends",0],"extensions":{"code":"FORBIDDEN"}}]}`
+		})
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/graphql")
+
+		got, err := Query[userResult](context.Background(), c, query, userVars{ID: "1"})
+		assert.Equal(t, "alice", got.User.Name)
+		assert.True(t, IsErrors(err))
+		assert.Equal(t, "graphql: forbidden (path: user.friends.0)", err.Error())
+
+		var gqlErr *Error
+		assert.True(t, errors.As(err, &gqlErr))
+		assert.Equal(t, "FORBIDDEN", gqlErr.Code())
+		assert.Equal(t, []Location{{Line: 1, Column: 30}}, gqlErr.Locations)
+		assert.Equal(t, []interface{}{"user", "friends", float64(0)}, gqlErr.Path)
+	})
+
+	t.Run("errors with non-2xx status", func(t *testing.T) {
+		srv := newServer(t, func(req gqlRequest) (int, string) {
+			return 400, `{"errors":[{"message":"syntax error","extensions":{"code":"GRAPHQL_PARSE_FAILED"}}]}`
+		})
+		for _, returnHTTPError := range []bool{false, true} {
+			c := New(httpClient.New(httpClient.Config{
+				Host:            srv.URL,
+				Timeout:         3000,
+				ReturnHTTPError: returnHTTPError,
+			}), "/graphql")
+
+			_, err := Query[userResult](context.Background(), c, "query {", struct{}{})
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Len(t, errs, 1)
+			assert.Equal(t, "GRAPHQL_PARSE_FAILED", errs[0].Code())
+		}
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		srv := newServer(t, func(req gqlRequest) (int, string) {
+			return 502, `bad gateway`
+		})
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/graphql")
+
+		_, err := Query[userResult](context.Background(), c, query, userVars{ID: "1"})
+		var httpErr *httpClient.HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, 502, httpErr.StatusCode)
+		assert.False(t, IsErrors(err))
+	})
+}
+
+func TestMutate(t *testing.T) {
+	const mutation = `mutation Rename($id: ID!, $name: String!) { rename(id: $id, name: $name) { name } }`
+
+	srv := newServer(t, func(req gqlRequest) (int, string) {
+		assert.Equal(t, mutation, req.Query)
+		assert.Equal(t, map[string]interface{}{"id": "1", "name": "bob"}, req.Variables)
+		return 200, `{"data":{"rename":{"name":"bob"}}}`
+	})
+	c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/graphql")
+
+	got, err := Mutate[map[string]map[string]string](context.Background(), c, mutation,
+		map[string]string{"id": "1", "name": "bob"})
+	assert.Nil(t, err)
+	assert.Equal(t, "bob", got["rename"]["name"])
+}
+
+func TestWithPersistedQueries(t *testing.T) {
+	const query = `query { me { name } }`
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+
+	var (
+		mu    sync.Mutex
+		cache = map[string]string{}
+		calls []gqlRequest
+	)
+	srv := newServer(t, func(req gqlRequest) (int, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, req)
+		assert.NotNil(t, req.Extensions)
+		assert.Equal(t, 1, req.Extensions.PersistedQuery.Version)
+		assert.Equal(t, hash, req.Extensions.PersistedQuery.Sha256Hash)
+		if req.Query != "" {
+			cache[req.Extensions.PersistedQuery.Sha256Hash] = req.Query
+		}
+		if _, ok := cache[req.Extensions.PersistedQuery.Sha256Hash]; !ok {
+			return 200, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`
+		}
+		return 200, `{"data":{"me":{"name":"alice"}}}`
+	})
+	c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/graphql", WithPersistedQueries())
+
+	type result struct {
+		Me struct {
+			Name string `json:"name"`
+		} `json:"me"`
+	}
+	for i := 0; i < 2; i++ {
+		got, err := Query[result](context.Background(), c, query, struct{}{})
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", got.Me.Name)
+	}
+
+	// hash only, hash & query once not found, then hash only
+	assert.Len(t, calls, 3)
+	assert.Equal(t, "", calls[0].Query)
+	assert.Equal(t, query, calls[1].Query)
+	assert.Equal(t, "", calls[2].Query)
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	TimestampUnix TimestampFormat = iota
+	TimestampUnixMilli
+	TimestampRFC3339
+)
+
+// TimestampFormat of signing timestamp
+type TimestampFormat int
+
+func (f TimestampFormat) String() string {
+	switch f {
+	case TimestampUnix:
+		return "unix"
+	case TimestampUnixMilli:
+		return "unix-milli"
+	case TimestampRFC3339:
+		return "rfc3339"
+	default:
+		return fmt.Sprintf("TimestampFormat(%d)", int(f))
+	}
+}
+
+func (f TimestampFormat) format(t time.Time) string {
+	switch f {
+	case TimestampUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case TimestampRFC3339:
+		return t.UTC().Format(time.RFC3339)
+	default:
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+}
+
+const (
+	EncodingHex SignatureEncoding = iota
+	EncodingBase64
+)
+
+// SignatureEncoding of HMAC signature sent in header
+type SignatureEncoding int
+
+func (e SignatureEncoding) String() string {
+	switch e {
+	case EncodingHex:
+		return "hex"
+	case EncodingBase64:
+		return "base64"
+	default:
+		return fmt.Sprintf("SignatureEncoding(%d)", int(e))
+	}
+}
+
+func (e SignatureEncoding) encode(b []byte) string {
+	if e == EncodingBase64 {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// DefaultHMACTemplate used when HMACConfig.Template is empty
+const DefaultHMACTemplate = "{method}\n{path}\n{query}\n{timestamp}\n{nonce}\n{body_hash}"
+
+// HMACConfig configure HMAC-SHA256 request signing
+type HMACConfig struct {
+	// KeyID identify Secret to the server, sent in KeyIDHeader when not empty
+	KeyID string
+	// Secret shared with the server
+	Secret []byte
+	// Template of canonical string, default DefaultHMACTemplate. Placeholders:
+	// {method}, {host}, {path} (escaped), {query} (raw), {timestamp}, {nonce},
+	// {body_hash} (hex SHA-256 of body) & {key_id}
+	Template string
+	// SignatureHeader default X-Signature, redacted in logs by default unless renamed
+	SignatureHeader string
+	// TimestampHeader default X-Timestamp
+	TimestampHeader string
+	// NonceHeader default X-Nonce, redacted in logs by default unless renamed
+	NonceHeader string
+	// KeyIDHeader default X-Key-Id
+	KeyIDHeader string
+	// TimestampFormat default TimestampUnix
+	TimestampFormat TimestampFormat
+	// Encoding of signature, default EncodingHex
+	Encoding SignatureEncoding
+	// ClockOffset added to local clock in milliseconds, for known skew against server
+	ClockOffset int
+	// SyncClock adjust clock offset using Date header of server responses
+	SyncClock bool
+	// Nonce generator, nil means 16 random bytes hex encoded
+	Nonce func() (string, error)
+}
+
+// HMACSigner sign requests using HMAC-SHA256, use it as Config.Auth or Request.WithAuth.
+// It runs after middlewares, so the signature covers the exact bytes being sent
+type HMACSigner struct {
+	// offset is time.Duration accessed atomically, first for 64-bit alignment
+	offset int64
+	cfg    HMACConfig
+	now    func() time.Time
+}
+
+func NewHMACSigner(cfg HMACConfig) *HMACSigner {
+	if cfg.Template == "" {
+		cfg.Template = DefaultHMACTemplate
+	}
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = "X-Signature"
+	}
+	if cfg.TimestampHeader == "" {
+		cfg.TimestampHeader = "X-Timestamp"
+	}
+	if cfg.NonceHeader == "" {
+		cfg.NonceHeader = "X-Nonce"
+	}
+	if cfg.KeyIDHeader == "" {
+		cfg.KeyIDHeader = "X-Key-Id"
+	}
+	if cfg.Nonce == nil {
+		cfg.Nonce = randomNonce
+	}
+	s := &HMACSigner{cfg: cfg, now: time.Now}
+	s.offset = int64(time.Duration(cfg.ClockOffset) * time.Millisecond)
+	return s
+}
+
+// Authenticate set timestamp, nonce & signature headers
+func (s *HMACSigner) Authenticate(req *http.Request) error {
+	hash, err := bodyHash(req)
+	if err != nil {
+		return fmt.Errorf("hmac: read body: %w", err)
+	}
+	nonce, err := s.cfg.Nonce()
+	if err != nil {
+		return fmt.Errorf("hmac: nonce: %w", err)
+	}
+	timestamp := s.cfg.TimestampFormat.format(s.now().Add(time.Duration(atomic.LoadInt64(&s.offset))))
+
+	canonical := strings.NewReplacer(
+		"{method}", req.Method,
+		"{host}", req.URL.Host,
+		"{path}", req.URL.EscapedPath(),
+		"{query}", req.URL.RawQuery,
+		"{timestamp}", timestamp,
+		"{nonce}", nonce,
+		"{body_hash}", hash,
+		"{key_id}", s.cfg.KeyID,
+	).Replace(s.cfg.Template)
+	mac := hmac.New(sha256.New, s.cfg.Secret)
+	mac.Write([]byte(canonical))
+
+	req.Header.Set(s.cfg.TimestampHeader, timestamp)
+	req.Header.Set(s.cfg.NonceHeader, nonce)
+	if s.cfg.KeyID != "" {
+		req.Header.Set(s.cfg.KeyIDHeader, s.cfg.KeyID)
+	}
+	req.Header.Set(s.cfg.SignatureHeader, s.cfg.Encoding.encode(mac.Sum(nil)))
+	return nil
+}
+
+// observe learn server clock offset from Date header
+func (s *HMACSigner) observe(_ *http.Request, resp *http.Response) {
+	if !s.cfg.SyncClock {
+		return
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	// Date has second precision, ignore differences below it
+	offset := date.Sub(s.now())
+	if offset > -time.Second && offset < time.Second {
+		offset = 0
+	}
+	atomic.StoreInt64(&s.offset, int64(offset))
+}
+
+// bodyHash return hex SHA-256 of request body without consuming it, body from GetBody is
+// streamed into the hash, only bodies without GetBody are buffered so they can still be sent
+func bodyHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return emptyPayloadHash, nil
+	}
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+		return hashHex(body), nil
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newHMACServer start server verifying signature of DefaultHMACTemplate over received bytes
+func newHMACServer(t *testing.T, secret string, serverTime time.Time) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !serverTime.IsZero() {
+			w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodyHash := sha256.Sum256(body)
+		canonical := strings.Join([]string{
+			r.Method,
+			r.URL.EscapedPath(),
+			r.URL.RawQuery,
+			r.Header.Get("X-Timestamp"),
+			r.Header.Get("X-Nonce"),
+			hex.EncodeToString(bodyHash[:]),
+		}, "\n")
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(canonical))
+		if r.Header.Get("X-Key-Id") != "partner" || r.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(r.Header.Get("X-Timestamp")))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHMACSigner(t *testing.T) {
+	srv := newHMACServer(t, "s3cret", time.Time{})
+	signer := NewHMACSigner(HMACConfig{KeyID: "partner", Secret: []byte("s3cret")})
+	c := New(Config{Host: srv.URL, Timeout: 3000, Auth: signer})
+
+	got, err := c.PostRaw(context.Background(), "/payments", []byte(`{"amount": 100}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+
+	got, err = c.WithPathParams(map[string]string{"id": "a b"}).
+		Get(context.Background(), "/payments/{id}", struct {
+			Page int `url:"page"`
+		}{Page: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+
+	// Case retry re-sign every attempt with the same body
+	var attempts int
+	retrySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer retrySrv.Close()
+	c = New(Config{
+		Host:    retrySrv.URL,
+		Timeout: 3000,
+		Auth:    signer,
+		Retry:   &RetryPolicy{MaxAttempts: 2, BaseDelay: 1, RetryNonIdempotent: true},
+	})
+	got, err = c.PostRaw(context.Background(), "/payments", []byte(`{"amount": 100}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Equal(t, 2, attempts)
+
+	// Case wrong secret
+	c = New(Config{
+		Host: srv.URL,
+		Auth: NewHMACSigner(HMACConfig{KeyID: "partner", Secret: []byte("other")}),
+	})
+	got, err = c.PostRaw(context.Background(), "/payments", []byte(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 401, got.StatusCode)
+}
+
+func TestHMACSigner_Authenticate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	signer := NewHMACSigner(HMACConfig{
+		Secret:          []byte("key"),
+		Template:        "{key_id}|{method}|{host}{path}|{timestamp}|{nonce}",
+		KeyID:           "k1",
+		SignatureHeader: "Signature",
+		TimestampHeader: "Date-Signed",
+		NonceHeader:     "Request-Id",
+		KeyIDHeader:     "Key",
+		TimestampFormat: TimestampRFC3339,
+		Encoding:        EncodingBase64,
+		ClockOffset:     -1500,
+		Nonce:           func() (string, error) { return "n1", nil },
+	})
+	signer.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest(http.MethodGet, "http://sample.internal/v1/orders", nil)
+	assert.Nil(t, signer.Authenticate(req))
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte("k1|GET|sample.internal/v1/orders|2024-05-01T09:59:58Z|n1"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get("Signature"))
+	assert.Equal(t, "2024-05-01T09:59:58Z", req.Header.Get("Date-Signed"))
+	assert.Equal(t, "n1", req.Header.Get("Request-Id"))
+	assert.Equal(t, "k1", req.Header.Get("Key"))
+
+	// Case body without GetBody is buffered & still sent
+	req, _ = http.NewRequest(http.MethodPost, "http://sample.internal/", io.NopCloser(strings.NewReader("payload")))
+	assert.Nil(t, signer.Authenticate(req))
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, "payload", string(body))
+
+	// Case body with GetBody is hashed from a fresh copy, body is untouched
+	streamed := NewHMACSigner(HMACConfig{Secret: []byte("key"), Template: "{body_hash}"})
+	req, _ = http.NewRequest(http.MethodPost, "http://sample.internal/", strings.NewReader("payload"))
+	assert.Nil(t, streamed.Authenticate(req))
+	sum := sha256.Sum256([]byte("payload"))
+	mac = hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte(hex.EncodeToString(sum[:])))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Signature"))
+	body, _ = io.ReadAll(req.Body)
+	assert.Equal(t, "payload", string(body))
+
+	// Case nonce failure
+	errNonce := errors.New("entropy exhausted")
+	signer.cfg.Nonce = func() (string, error) { return "", errNonce }
+	assert.ErrorIs(t, signer.Authenticate(req), errNonce)
+}
+
+func TestHMACSigner_SyncClock(t *testing.T) {
+	serverTime := time.Now().Add(time.Hour)
+	srv := newHMACServer(t, "s3cret", serverTime)
+	c := New(Config{
+		Host: srv.URL,
+		Auth: NewHMACSigner(HMACConfig{KeyID: "partner", Secret: []byte("s3cret"), SyncClock: true}),
+	})
+
+	got, err := c.Get(context.Background(), "/first", nil)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(got.Body)
+	first, _ := strconv.ParseInt(string(body), 10, 64)
+	assert.InDelta(t, time.Now().Unix(), first, 2)
+
+	got, err = c.Get(context.Background(), "/second", nil)
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(got.Body)
+	second, _ := strconv.ParseInt(string(body), 10, 64)
+	assert.InDelta(t, serverTime.Unix(), second, 2)
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cymon1997/go-client/http/codec"
+)
+
+// GetJSON send GET request & decode 2xx response body into T,
+// c can be Client or Request built using Client.WithHeaders etc.
+func GetJSON[T any](ctx context.Context, c Request, endpoint string, params interface{}) (T, *http.Response, error) {
+	return DecodeResponse[T](c.WithCodec(codec.JSON).Get(ctx, endpoint, params))
+}
+
+// PostJSON send POST request with body encoded as JSON & decode 2xx response body into Res
+func PostJSON[Req, Res any](ctx context.Context, c Request, endpoint string, body Req) (Res, *http.Response, error) {
+	return DecodeResponse[Res](c.WithCodec(codec.JSON).Post(ctx, endpoint, body))
+}
+
+// PutJSON send PUT request with body encoded as JSON & decode 2xx response body into Res
+func PutJSON[Req, Res any](ctx context.Context, c Request, endpoint string, body Req) (Res, *http.Response, error) {
+	return DecodeResponse[Res](c.WithCodec(codec.JSON).Put(ctx, endpoint, body))
+}
+
+// PatchJSON send PATCH request with body encoded as JSON & decode 2xx response body into Res
+func PatchJSON[Req, Res any](ctx context.Context, c Request, endpoint string, body Req) (Res, *http.Response, error) {
+	return DecodeResponse[Res](c.WithCodec(codec.JSON).Patch(ctx, endpoint, body))
+}
+
+// DeleteJSON send DELETE request & decode 2xx response body into T
+func DeleteJSON[T any](ctx context.Context, c Request, endpoint string) (T, *http.Response, error) {
+	return DecodeResponse[T](c.WithCodec(codec.JSON).Delete(ctx, endpoint))
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type sampleData struct {
+	Data string `json:"data"`
+}
+
+func TestGetJSON(t *testing.T) {
+	t.Run("http.GetJSON", func(t *testing.T) {
+		defer gock.OffAll()
+
+		c := New(Config{
+			Host:    "http://localhost:8000",
+			Timeout: 3000,
+		})
+
+		// Case success
+		gock.New("http://localhost:8000").
+			Get("/get").
+			Reply(200).
+			JSON(map[string]string{"data": "some_data"})
+
+		got, resp, err := GetJSON[sampleData](context.Background(), c, "/get", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "some_data", got.Data)
+
+		// Case invalid body
+		gock.New("http://localhost:8000").
+			Get("/get").
+			Reply(200).
+			BodyString(`{`)
+
+		_, _, err = GetJSON[sampleData](context.Background(), c, "/get", nil)
+		assert.Error(t, err)
+
+		// Case non-2xx
+		gock.New("http://localhost:8000").
+			Get("/get").
+			Reply(404).
+			JSON(map[string]string{"message": "not found"})
+
+		_, resp, err = GetJSON[sampleData](context.Background(), c, "/get", nil)
+		assert.Equal(t, 404, resp.StatusCode)
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, 404, httpErr.StatusCode)
+		assert.Equal(t, map[string]interface{}{"message": "not found"}, httpErr.Payload)
+
+		var payload struct {
+			Message string `json:"message"`
+		}
+		assert.Nil(t, httpErr.Decode(&payload))
+		assert.Equal(t, "not found", payload.Message)
+	})
+}
+
+func TestPostJSON(t *testing.T) {
+	t.Run("http.PostJSON", func(t *testing.T) {
+		defer gock.OffAll()
+
+		c := &clientImpl{
+			client: &http.Client{
+				Timeout: 3000 * time.Millisecond,
+			},
+			baseURL: "http://localhost:8000",
+		}
+
+		// Case success
+		gock.New("http://localhost:8000").
+			Post("/post").
+			MatchHeader("Custom-Header", "custom_value").
+			BodyString(`{"data":"request"}`).
+			Reply(201).
+			JSON(map[string]string{"data": "response"})
+
+		got, resp, err := PostJSON[sampleData, sampleData](context.Background(),
+			c.WithHeaders(map[string]string{"Custom-Header": "custom_value"}),
+			"/post", sampleData{Data: "request"})
+		assert.Nil(t, err)
+		assert.Equal(t, 201, resp.StatusCode)
+		assert.Equal(t, "response", got.Data)
+
+		// Case no content
+		gock.New("http://localhost:8000").
+			Delete("/delete/1").
+			Reply(204)
+
+		deleted, resp, err := DeleteJSON[*sampleData](context.Background(), c, "/delete/1")
+		assert.Nil(t, err)
+		assert.Equal(t, 204, resp.StatusCode)
+		assert.Nil(t, deleted)
+
+		// Case non-JSON error body
+		gock.New("http://localhost:8000").
+			Put("/put").
+			Reply(500).
+			BodyString("internal error")
+
+		_, _, err = PutJSON[sampleData, sampleData](context.Background(), c, "/put", sampleData{})
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, []byte("internal error"), httpErr.Body)
+		assert.Nil(t, httpErr.Payload)
+	})
+}
@@ -0,0 +1,267 @@
+// Package jsonrpc provide JSON-RPC 2.0 client on top of http.Client
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/cymon1997/go-client/http/util"
+)
+
+const version = "2.0"
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// ErrMissingResponse set on a batch call when server response does not contain its ID
+var ErrMissingResponse = errors.New("jsonrpc: missing response")
+
+// Error is a JSON-RPC error object returned by server
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Data additional server specific information, decode using json.Unmarshal
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (code %d)", e.Message, e.Code)
+}
+
+// IsErrorCode check whether err is a JSON-RPC error with code
+func IsErrorCode(err error, code int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == code
+}
+
+// Client send JSON-RPC 2.0 requests to a single endpoint, request IDs are
+// generated from a counter shared by all calls of the client
+type Client struct {
+	// id accessed atomically, first for 64-bit alignment
+	id       uint64
+	client   httpClient.Client
+	endpoint string
+}
+
+// New create JSON-RPC client sending requests to endpoint using c,
+// headers, auth, retry etc. of c apply to every request
+func New(c httpClient.Client, endpoint string) *Client {
+	return &Client{client: c, endpoint: endpoint}
+}
+
+// Call invoke method with params & decode result into T, params must encode
+// as JSON array or object, nil params (e.g. Call[T, any]) are omitted
+func Call[T, P any](ctx context.Context, c *Client, method string, params P) (T, error) {
+	var res T
+	req, err := c.request(method, params, true)
+	if err != nil {
+		return res, err
+	}
+	raw, err := c.send(ctx, req)
+	if err != nil {
+		return res, err
+	}
+	var resp response
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return res, err
+	}
+	if err := resp.decode(&res); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// Notify send notification, server does not reply so only transport & HTTP errors are returned
+func (c *Client) Notify(ctx context.Context, method string, params interface{}) error {
+	req, err := c.request(method, params, false)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, req)
+	return err
+}
+
+// Batch create empty batch sent using Batch.Send
+func (c *Client) Batch() *Batch {
+	return &Batch{client: c}
+}
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *Error          `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func (r *response) decode(dest interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if dest == nil || len(r.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Result, dest)
+}
+
+// request build request, notifications have no ID
+func (c *Client) request(method string, params interface{}, call bool) (*request, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	req := &request{Version: version, Method: method}
+	if string(raw) != "null" {
+		req.Params = raw
+	}
+	if call {
+		req.ID = json.RawMessage(strconv.FormatUint(atomic.AddUint64(&c.id, 1), 10))
+	}
+	return req, nil
+}
+
+// send post body & return response body, empty when server reply nothing.
+// Non-2xx response carrying a JSON-RPC error return *Error, otherwise *http.HTTPError
+func (c *Client) send(ctx context.Context, body interface{}) ([]byte, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.WithHeaders(map[string]string{"Content-Type": "application/json"}).
+		PostRaw(ctx, c.endpoint, raw)
+	var httpErr *httpClient.HTTPError
+	if errors.As(err, &httpErr) {
+		return nil, errorResponse(httpErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !util.IsStatusOK(resp) {
+		httpErr, err := httpClient.NewHTTPError(resp)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errorResponse(httpErr)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// errorResponse use JSON-RPC error carried by non-2xx response body, if any
+func errorResponse(httpErr *httpClient.HTTPError) error {
+	var resp response
+	if json.Unmarshal(httpErr.Body, &resp) == nil && resp.Error != nil {
+		return resp.Error
+	}
+	return httpErr
+}
+
+// BatchCall is a call queued in Batch, Err is set once Batch.Send returned
+type BatchCall struct {
+	Method string
+	Params interface{}
+	// Result destination pointer the result is decoded into, nil to discard
+	Result interface{}
+	// Err *Error returned by server, params encoding or decoding error, or ErrMissingResponse
+	Err error
+
+	req *request
+}
+
+// Batch queue calls & notifications sent in a single HTTP request,
+// responses are matched to calls by ID regardless of their order
+type Batch struct {
+	client *Client
+	calls  []*BatchCall
+	reqs   []*request
+}
+
+// Call queue call of method, result is decoded into result pointer
+func (b *Batch) Call(method string, params, result interface{}) *BatchCall {
+	call := &BatchCall{Method: method, Params: params, Result: result}
+	call.req, call.Err = b.client.request(method, params, true)
+	b.calls = append(b.calls, call)
+	if call.Err == nil {
+		b.reqs = append(b.reqs, call.req)
+	}
+	return call
+}
+
+// Notify queue notification of method, returning params encoding error
+func (b *Batch) Notify(method string, params interface{}) error {
+	req, err := b.client.request(method, params, false)
+	if err != nil {
+		return err
+	}
+	b.reqs = append(b.reqs, req)
+	return nil
+}
+
+// Send send queued requests, the returned error covers the whole batch
+// (transport, HTTP or invalid response), per call errors are set in BatchCall.Err
+func (b *Batch) Send(ctx context.Context) error {
+	if len(b.reqs) == 0 {
+		return nil
+	}
+	raw, err := b.client.send(ctx, b.reqs)
+	if err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		// notifications only, server reply nothing
+		return nil
+	}
+
+	var resps []response
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '{' {
+		// server reject the whole batch with a single error response
+		var resp response
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		resps = append(resps, resp)
+	} else if err := json.Unmarshal(raw, &resps); err != nil {
+		return err
+	}
+
+	byID := make(map[string]*response, len(resps))
+	for i := range resps {
+		byID[string(bytes.TrimSpace(resps[i].ID))] = &resps[i]
+	}
+	for _, call := range b.calls {
+		if call.req == nil {
+			continue
+		}
+		resp, ok := byID[string(call.req.ID)]
+		if !ok {
+			call.Err = ErrMissingResponse
+			continue
+		}
+		call.Err = resp.decode(call.Result)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/stretchr/testify/assert"
+)
+
+type rpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcServer serve add, fail & log methods, log is recorded & never replied
+type rpcServer struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (s *rpcServer) handle(req rpcRequest) interface{} {
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "add":
+		var params []int
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp["error"] = map[string]interface{}{"code": CodeInvalidParams, "message": "Invalid params"}
+			break
+		}
+		sum := 0
+		for _, p := range params {
+			sum += p
+		}
+		resp["result"] = sum
+	case "fail":
+		resp["error"] = map[string]interface{}{"code": -32000, "message": "boom", "data": map[string]string{"reason": "test"}}
+	case "log":
+		var params struct {
+			Msg string `json:"msg"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		s.mu.Lock()
+		s.logs = append(s.logs, params.Msg)
+		s.mu.Unlock()
+	default:
+		resp["error"] = map[string]interface{}{"code": CodeMethodNotFound, "message": "Method not found"}
+	}
+	if req.ID == nil {
+		return nil
+	}
+	return resp
+}
+
+func newServer(t *testing.T, s *rpcServer) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var raw json.RawMessage
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&raw))
+
+		var res interface{}
+		if raw[0] == '[' {
+			var reqs []rpcRequest
+			assert.Nil(t, json.Unmarshal(raw, &reqs))
+			resps := []interface{}{}
+			// reply in reverse order, clients must match by ID
+			for i := len(reqs) - 1; i >= 0; i-- {
+				if resp := s.handle(reqs[i]); resp != nil {
+					resps = append(resps, resp)
+				}
+			}
+			if len(resps) > 0 {
+				res = resps
+			}
+		} else {
+			var req rpcRequest
+			assert.Nil(t, json.Unmarshal(raw, &req))
+			assert.Equal(t, "2.0", req.Version)
+			res = s.handle(req)
+		}
+		if res == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCall(t *testing.T) {
+	srv := newServer(t, &rpcServer{})
+	c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/rpc")
+
+	t.Run("result", func(t *testing.T) {
+		got, err := Call[int](context.Background(), c, "add", []int{1, 2, 3})
+		assert.Nil(t, err)
+		assert.Equal(t, 6, got)
+	})
+
+	t.Run("error object", func(t *testing.T) {
+		_, err := Call[int](context.Background(), c, "fail", []int{})
+		var rpcErr *Error
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, -32000, rpcErr.Code)
+		assert.Equal(t, "jsonrpc: boom (code -32000)", rpcErr.Error())
+		assert.JSONEq(t, `{"reason":"test"}`, string(rpcErr.Data))
+
+		_, err = Call[int, any](context.Background(), c, "unknown", nil)
+		assert.True(t, IsErrorCode(err, CodeMethodNotFound))
+	})
+
+	t.Run("unique ids", func(t *testing.T) {
+		a, _ := c.request("add", nil, true)
+		b, _ := c.request("add", nil, true)
+		assert.NotEqual(t, a.ID, b.ID)
+		assert.Nil(t, a.Params)
+	})
+}
+
+func TestClient_Notify(t *testing.T) {
+	s := &rpcServer{}
+	srv := newServer(t, s)
+	c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/rpc")
+
+	err := c.Notify(context.Background(), "log", map[string]string{"msg": "hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"hello"}, s.logs)
+}
+
+func TestBatch_Send(t *testing.T) {
+	t.Run("matched by id", func(t *testing.T) {
+		s := &rpcServer{}
+		srv := newServer(t, s)
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/rpc")
+
+		var sum1, sum2 int
+		b := c.Batch()
+		call1 := b.Call("add", []int{1, 2}, &sum1)
+		assert.Nil(t, b.Notify("log", map[string]string{"msg": "batched"}))
+		call2 := b.Call("add", []int{10, 20}, &sum2)
+		call3 := b.Call("fail", nil, nil)
+
+		assert.Nil(t, b.Send(context.Background()))
+		assert.Nil(t, call1.Err)
+		assert.Equal(t, 3, sum1)
+		assert.Nil(t, call2.Err)
+		assert.Equal(t, 30, sum2)
+		assert.True(t, IsErrorCode(call3.Err, -32000))
+		assert.Equal(t, []string{"batched"}, s.logs)
+	})
+
+	t.Run("notifications only", func(t *testing.T) {
+		s := &rpcServer{}
+		srv := newServer(t, s)
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/rpc")
+
+		b := c.Batch()
+		assert.Nil(t, b.Notify("log", map[string]string{"msg": "a"}))
+		assert.Nil(t, b.Notify("log", map[string]string{"msg": "b"}))
+		assert.Nil(t, b.Send(context.Background()))
+		assert.ElementsMatch(t, []string{"a", "b"}, s.logs)
+	})
+
+	t.Run("missing response", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"jsonrpc":"2.0","id":999,"result":1}]`))
+		}))
+		defer srv.Close()
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/rpc")
+
+		b := c.Batch()
+		call := b.Call("add", []int{1}, nil)
+		assert.Nil(t, b.Send(context.Background()))
+		assert.Equal(t, ErrMissingResponse, call.Err)
+	})
+
+	t.Run("whole batch rejected", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid Request"}}`))
+		}))
+		defer srv.Close()
+		c := New(httpClient.New(httpClient.Config{Host: srv.URL, Timeout: 3000}), "/rpc")
+
+		b := c.Batch()
+		b.Call("add", []int{1}, nil)
+		assert.True(t, IsErrorCode(b.Send(context.Background()), CodeInvalidRequest))
+	})
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/cymon1997/go-client/http/util"
+)
+
+// JSONStream iterate records of a JSON response one at a time, decoding one record at a time
+// so memory is bounded by the largest record, e.g.
+//
+//	stream := StreamJSONLines[Event](ctx, resp)
+//	defer stream.Close()
+//	for stream.Next() {
+//		event := stream.Value()
+//		...
+//	}
+//	if err := stream.Err(); err != nil {
+//		return err
+//	}
+//
+// Non-2xx response is reported by Err as *HTTPError. Next return false after the first error or
+// when ctx is done, response body is closed then or by Close & can only be iterated once
+type JSONStream[T any] struct {
+	ctx     context.Context
+	resp    *http.Response
+	dec     *json.Decoder
+	array   bool
+	started bool
+	done    bool
+	value   T
+	err     error
+	// stop is closed by Close to end the ctx watcher
+	stop      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// StreamJSONLines iterate records of newline-delimited JSON (NDJSON / JSON lines) response
+func StreamJSONLines[T any](ctx context.Context, resp *http.Response) *JSONStream[T] {
+	return streamJSON[T](ctx, resp, false)
+}
+
+// StreamJSONArray iterate elements of JSON array response, see StreamJSONLines
+func StreamJSONArray[T any](ctx context.Context, resp *http.Response) *JSONStream[T] {
+	return streamJSON[T](ctx, resp, true)
+}
+
+// streamJSON decode consecutive records, or elements when array is set
+func streamJSON[T any](ctx context.Context, resp *http.Response, array bool) *JSONStream[T] {
+	s := &JSONStream[T]{
+		ctx:   ctx,
+		resp:  resp,
+		dec:   json.NewDecoder(resp.Body),
+		array: array,
+		stop:  make(chan struct{}),
+	}
+	if !util.IsStatusOK(resp) {
+		httpErr, err := NewHTTPError(resp)
+		if err == nil {
+			err = httpErr
+		}
+		s.err = err
+		_ = s.Close()
+		return s
+	}
+	// unblock pending read when ctx is done
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = resp.Body.Close()
+		case <-s.stop:
+		}
+	}()
+	return s
+}
+
+// Next decode the next record, returning false when there is none left or on error
+func (s *JSONStream[T]) Next() bool {
+	if s.done {
+		return false
+	}
+	if !s.started {
+		s.started = true
+		if s.array {
+			if err := expectDelim(s.dec, '['); err != nil {
+				return s.finish(err)
+			}
+		}
+	}
+	if s.array && !s.dec.More() {
+		return s.finish(expectDelim(s.dec, ']'))
+	}
+	var record T
+	if err := s.dec.Decode(&record); err != nil || s.ctx.Err() != nil {
+		return s.finish(err)
+	}
+	s.value = record
+	return true
+}
+
+// Value return the record decoded by the last Next
+func (s *JSONStream[T]) Value() T {
+	return s.value
+}
+
+// Err return the error that stopped iteration, nil when all records are decoded
+func (s *JSONStream[T]) Err() error {
+	return s.err
+}
+
+// Close stop iteration & close response body, safe to call more than once
+func (s *JSONStream[T]) Close() error {
+	s.done = true
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		s.closeErr = s.resp.Body.Close()
+	})
+	return s.closeErr
+}
+
+// finish end iteration with err, ctx error take precedence & io.EOF means no more records
+func (s *JSONStream[T]) finish(err error) bool {
+	switch {
+	case s.ctx.Err() != nil:
+		s.err = s.ctx.Err()
+	case errors.Is(err, io.EOF):
+		s.err = nil
+	default:
+		s.err = err
+	}
+	var zero T
+	s.value = zero
+	_ = s.Close()
+	return false
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("http: expected %v in JSON array, got %v", delim, tok)
+	}
+	return nil
+}
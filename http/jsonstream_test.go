@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type record struct {
+	ID int `json:"id"`
+}
+
+func collect[T any](stream *JSONStream[T]) ([]T, error) {
+	defer stream.Close()
+	var res []T
+	for stream.Next() {
+		res = append(res, stream.Value())
+	}
+	return res, stream.Err()
+}
+
+func newJSONResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestStreamJSONLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		want    []record
+		wantErr string
+	}{
+		{
+			name: "lines",
+			resp: newJSONResponse(200, "{\"id\":1}\n{\"id\":2}\r\n\n{\"id\":3}\n"),
+			want: []record{{ID: 1}, {ID: 2}, {ID: 3}},
+		},
+		{
+			name: "empty",
+			resp: newJSONResponse(200, ""),
+		},
+		{
+			name:    "malformed record",
+			resp:    newJSONResponse(200, "{\"id\":1}\n{\"id\":\n"),
+			want:    []record{{ID: 1}},
+			wantErr: "unexpected EOF",
+		},
+		{
+			name:    "non-2xx",
+			resp:    newJSONResponse(500, `{"error":"boom"}`),
+			wantErr: "unexpected status 500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collect(StreamJSONLines[record](context.Background(), tt.resp))
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []record
+		wantErr string
+	}{
+		{name: "array", body: `[{"id":1}, {"id":2}]`, want: []record{{ID: 1}, {ID: 2}}},
+		{name: "empty array", body: ` [ ] `},
+		{name: "not array", body: `{"id":1}`, wantErr: "expected [ in JSON array"},
+		{name: "truncated", body: `[{"id":1}`, want: []record{{ID: 1}}, wantErr: "unexpected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collect(StreamJSONArray[record](context.Background(), newJSONResponse(200, tt.body)))
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestStreamJSONLines_cancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"id\":1}\n{\"id\":2}\n"))
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	// response body outlive request ctx, only iteration ctx is cancelled
+	resp, err := New(Config{Host: srv.URL}).Get(context.Background(), "/events", nil)
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []record
+	stream := StreamJSONLines[record](ctx, resp)
+	for stream.Next() {
+		got = append(got, stream.Value())
+		if stream.Value().ID == 2 {
+			cancel()
+		}
+	}
+	assert.ErrorIs(t, stream.Err(), context.Canceled)
+	assert.Equal(t, []record{{ID: 1}, {ID: 2}}, got)
+
+	// Case Close before the end close body
+	resp = newJSONResponse(200, "{\"id\":1}\n{\"id\":2}\n")
+	closed := &closeRecorder{Reader: resp.Body}
+	resp.Body = closed
+	stream = StreamJSONLines[record](context.Background(), resp)
+	assert.True(t, stream.Next())
+	assert.Nil(t, stream.Close())
+	assert.True(t, closed.closed)
+	assert.False(t, stream.Next())
+	assert.Nil(t, stream.Err())
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// LogLevel severity of a log entry
+type LogLevel int
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// Field is a structured log attribute
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger receive structured request & response logs, see NewSlogLogger for log/slog adapter
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, fields ...Field)
+}
+
+// LoggerFunc adapt ordinary function as Logger
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, fields ...Field)
+
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	f(ctx, level, msg, fields...)
+}
+
+// redacted replace value of sensitive headers & query params
+const redacted = "REDACTED"
+
+var (
+	// DefaultRedactHeaders used when LogConfig.RedactHeaders is nil
+	DefaultRedactHeaders = []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Api-Key",
+		"X-Amz-Security-Token",
+		"X-Signature",
+		"X-Nonce",
+	}
+	// DefaultRedactQuery used when LogConfig.RedactQuery is nil
+	DefaultRedactQuery = []string{
+		"token",
+		"access_token",
+		"api_key",
+		"apikey",
+		"key",
+		"password",
+		"secret",
+		"signature",
+	}
+)
+
+// LogConfig configure request & response logging
+type LogConfig struct {
+	Logger Logger
+	// Level of request & response logs, failed attempts (error or 5xx) use at least LevelWarn
+	Level LogLevel
+	// LogHeaders include request headers in request log
+	LogHeaders bool
+	// RedactHeaders header names to redact (case-insensitive), nil means DefaultRedactHeaders
+	RedactHeaders []string
+	// RedactQuery query param names to redact (case-insensitive), nil means DefaultRedactQuery
+	RedactQuery []string
+}
+
+// requestLogger log every attempt sent by a client
+type requestLogger struct {
+	cfg     LogConfig
+	headers map[string]bool
+	query   map[string]bool
+}
+
+func newRequestLogger(cfg *LogConfig) *requestLogger {
+	if cfg == nil || cfg.Logger == nil {
+		return nil
+	}
+	headers, query := cfg.RedactHeaders, cfg.RedactQuery
+	if headers == nil {
+		headers = DefaultRedactHeaders
+	}
+	if query == nil {
+		query = DefaultRedactQuery
+	}
+	return &requestLogger{
+		cfg:     *cfg,
+		headers: lowerSet(headers),
+		query:   lowerSet(query),
+	}
+}
+
+// wrap next with logging, nil logger return next as is
+func (l *requestLogger) wrap(next Doer) Doer {
+	if l == nil {
+		return next
+	}
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		fields := []Field{
+			{Key: "method", Value: req.Method},
+			{Key: "url", Value: l.redactURL(req.URL)},
+			{Key: "endpoint", Value: EndpointTemplate(ctx)},
+			{Key: "attempt", Value: Attempt(ctx)},
+		}
+		if l.cfg.LogHeaders {
+			l.cfg.Logger.Log(ctx, l.cfg.Level, "http request",
+				append(fields, Field{Key: "headers", Value: l.redactHeaders(req.Header)})...)
+		} else {
+			l.cfg.Logger.Log(ctx, l.cfg.Level, "http request", fields...)
+		}
+
+		start := time.Now()
+		resp, err := next.Do(req)
+		fields = append(fields, Field{Key: "latency", Value: time.Since(start)})
+
+		level := l.cfg.Level
+		if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+			if level < LevelWarn {
+				level = LevelWarn
+			}
+		}
+		if err != nil {
+			l.cfg.Logger.Log(ctx, level, "http response", append(fields, Field{Key: "error", Value: err.Error()})...)
+		} else {
+			l.cfg.Logger.Log(ctx, level, "http response", append(fields, Field{Key: "status", Value: resp.StatusCode})...)
+		}
+		return resp, err
+	})
+}
+
+func (l *requestLogger) redactURL(u *url.URL) string {
+	if u.RawQuery == "" && u.User == nil {
+		return u.String()
+	}
+	res := *u
+	if res.User != nil {
+		res.User = url.User(res.User.Username())
+	}
+	values := res.Query()
+	for k := range values {
+		if l.query[strings.ToLower(k)] {
+			values[k] = []string{redacted}
+		}
+	}
+	res.RawQuery = values.Encode()
+	return res.String()
+}
+
+func (l *requestLogger) redactHeaders(header http.Header) map[string]string {
+	res := make(map[string]string, len(header))
+	for k := range header {
+		if l.headers[strings.ToLower(k)] {
+			res[k] = redacted
+		} else {
+			res[k] = header.Get(k)
+		}
+	}
+	return res
+}
+
+func lowerSet(keys []string) map[string]bool {
+	res := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		res[strings.ToLower(k)] = true
+	}
+	return res
+}
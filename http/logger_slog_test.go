@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})))
+
+	logger.Log(context.Background(), LevelWarn, "http response",
+		Field{Key: "method", Value: "GET"},
+		Field{Key: "status", Value: 503},
+	)
+
+	var got map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "WARN", got["level"])
+	assert.Equal(t, "http response", got["msg"])
+	assert.Equal(t, "GET", got["method"])
+	assert.Equal(t, float64(503), got["status"])
+}
@@ -0,0 +1,141 @@
+// Package metrics provide Prometheus RED metrics for http.Client
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options of collectors, all fields are optional
+type Options struct {
+	Namespace string
+	Subsystem string
+	// Buckets of request duration histogram in seconds, default prometheus.DefBuckets
+	Buckets []float64
+	// ConstLabels attached to all collectors, e.g. client name
+	ConstLabels prometheus.Labels
+	// EndpointLabel map request to a bounded endpoint label, default: endpoint template when
+	// path params are set (see Request.WithPathParams), unknownEndpoint otherwise
+	EndpointLabel func(req *http.Request) string
+}
+
+// unknownEndpoint label of requests without path params, their raw endpoint may contain IDs
+const unknownEndpoint = "unknown"
+
+// Metrics hold collectors of client requests, endpoint labels never use raw paths
+// to keep cardinality bounded, see Options.EndpointLabel
+type Metrics struct {
+	endpoint func(req *http.Request) string
+	duration *prometheus.HistogramVec
+	requests *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+	retries  *prometheus.CounterVec
+	circuit  *prometheus.CounterVec
+}
+
+// New create & register collectors on reg
+func New(reg prometheus.Registerer, opts Options) (*Metrics, error) {
+	m := &Metrics{
+		endpoint: opts.EndpointLabel,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "http_client_request_duration_seconds",
+			Help:        "Duration of outbound http requests.",
+			Buckets:     opts.Buckets,
+			ConstLabels: opts.ConstLabels,
+		}, []string{"host", "method", "endpoint", "status_class"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "http_client_requests_total",
+			Help:        "Total outbound http requests.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"host", "method", "endpoint", "status_class"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "http_client_requests_in_flight",
+			Help:        "Outbound http requests currently in flight.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"host"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "http_client_retries_total",
+			Help:        "Total retry attempts of outbound http requests.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"host", "method", "endpoint"}),
+		circuit: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "http_client_circuit_transitions_total",
+			Help:        "Total circuit breaker state transitions.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"key", "state"}),
+	}
+	if m.endpoint == nil {
+		m.endpoint = endpointTemplate
+	}
+	collectors := []prometheus.Collector{m.duration, m.requests, m.inFlight, m.retries, m.circuit}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			// leave reg as it was so New can be retried
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Middleware record duration, status, in-flight & retry metrics of every attempt,
+// register it using Client.Use
+func (m *Metrics) Middleware() httpClient.Middleware {
+	return func(next httpClient.Doer) httpClient.Doer {
+		return httpClient.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			host := req.URL.Host
+			endpoint := m.endpoint(req)
+			if httpClient.Attempt(ctx) > 1 {
+				m.retries.WithLabelValues(host, req.Method, endpoint).Inc()
+			}
+
+			inFlight := m.inFlight.WithLabelValues(host)
+			inFlight.Inc()
+			start := time.Now()
+			resp, err := next.Do(req)
+			inFlight.Dec()
+
+			class := statusClass(resp, err)
+			m.duration.WithLabelValues(host, req.Method, endpoint, class).Observe(time.Since(start).Seconds())
+			m.requests.WithLabelValues(host, req.Method, endpoint, class).Inc()
+			return resp, err
+		})
+	}
+}
+
+// OnStateChange count circuit breaker transitions, set it as BreakerConfig.OnStateChange
+func (m *Metrics) OnStateChange(key string, _, to httpClient.BreakerState) {
+	m.circuit.WithLabelValues(key, to.String()).Inc()
+}
+
+func endpointTemplate(req *http.Request) string {
+	if !httpClient.HasPathParams(req.Context()) {
+		return unknownEndpoint
+	}
+	return httpClient.EndpointTemplate(req.Context())
+}
+
+// statusClass return 1xx-5xx, or error when no response received
+func statusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
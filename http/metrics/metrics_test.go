@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestNew(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := New(reg, Options{})
+	assert.Nil(t, err)
+
+	// duplicate registration
+	_, err = New(reg, Options{})
+	assert.Error(t, err)
+
+	// failed registration leave no collector behind
+	reg = prometheus.NewRegistry()
+	_, err = New(&failingRegisterer{Registerer: reg, n: 2}, Options{})
+	assert.Error(t, err)
+	_, err = New(reg, Options{})
+	assert.Nil(t, err)
+}
+
+// failingRegisterer fail every registration after the first n
+type failingRegisterer struct {
+	prometheus.Registerer
+	n int
+}
+
+func (r *failingRegisterer) Register(c prometheus.Collector) error {
+	if r.n == 0 {
+		return errors.New("registry full")
+	}
+	r.n--
+	return r.Registerer.Register(c)
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	t.Run("metrics.Middleware", func(t *testing.T) {
+		defer gock.OffAll()
+
+		reg := prometheus.NewRegistry()
+		m, err := New(reg, Options{Namespace: "sample"})
+		assert.Nil(t, err)
+
+		c := httpClient.New(httpClient.Config{
+			Host:    "http://localhost:8000",
+			Timeout: 3000,
+			Retry:   &httpClient.RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+			Breaker: &httpClient.BreakerConfig{
+				ConsecutiveFailures: 2,
+				CoolDown:            60000,
+				OnStateChange:       m.OnStateChange,
+			},
+		})
+		c.Use(m.Middleware())
+
+		gock.New("http://localhost:8000").Get("/users/1").Reply(503)
+		gock.New("http://localhost:8000").Get("/users/1").Reply(200)
+		gock.New("http://localhost:8000").Get("/users/2").Reply(200)
+
+		for _, id := range []string{"1", "2"} {
+			got, err := c.WithPathParams(map[string]string{"id": id}).
+				Get(context.Background(), "/users/{id}", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, 200, got.StatusCode)
+		}
+
+		err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP sample_http_client_requests_total Total outbound http requests.
+# TYPE sample_http_client_requests_total counter
+sample_http_client_requests_total{endpoint="/users/{id}",host="localhost:8000",method="GET",status_class="2xx"} 2
+sample_http_client_requests_total{endpoint="/users/{id}",host="localhost:8000",method="GET",status_class="5xx"} 1
+# HELP sample_http_client_retries_total Total retry attempts of outbound http requests.
+# TYPE sample_http_client_retries_total counter
+sample_http_client_retries_total{endpoint="/users/{id}",host="localhost:8000",method="GET"} 1
+# HELP sample_http_client_requests_in_flight Outbound http requests currently in flight.
+# TYPE sample_http_client_requests_in_flight gauge
+sample_http_client_requests_in_flight{host="localhost:8000"} 0
+`), "sample_http_client_requests_total", "sample_http_client_retries_total", "sample_http_client_requests_in_flight")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, testutil.CollectAndCount(m.duration.WithLabelValues("localhost:8000", "GET", "/users/{id}", "5xx").(prometheus.Histogram)))
+	})
+}
+
+func TestMetrics_Middleware_endpointLabel(t *testing.T) {
+	t.Run("raw path never used", func(t *testing.T) {
+		defer gock.OffAll()
+
+		reg := prometheus.NewRegistry()
+		m, err := New(reg, Options{})
+		assert.Nil(t, err)
+		c := httpClient.New(httpClient.Config{Host: "http://localhost:8000", Timeout: 3000})
+		c.Use(m.Middleware())
+
+		gock.New("http://localhost:8000").Delete("/delete/1").Reply(200)
+		gock.New("http://localhost:8000").Delete("/delete/2").Reply(200)
+		for _, endpoint := range []string{"/delete/1", "/delete/2"} {
+			_, err := c.Delete(context.Background(), endpoint)
+			assert.Nil(t, err)
+		}
+
+		assert.Equal(t, 1, testutil.CollectAndCount(m.requests))
+		assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("localhost:8000", "DELETE", "unknown", "2xx")))
+	})
+
+	t.Run("custom label", func(t *testing.T) {
+		defer gock.OffAll()
+
+		reg := prometheus.NewRegistry()
+		m, err := New(reg, Options{
+			EndpointLabel: func(req *http.Request) string {
+				return strings.Join(strings.Split(req.URL.Path, "/")[:2], "/")
+			},
+		})
+		assert.Nil(t, err)
+		c := httpClient.New(httpClient.Config{Host: "http://localhost:8000", Timeout: 3000})
+		c.Use(m.Middleware())
+
+		gock.New("http://localhost:8000").Get("/health").Reply(200)
+		_, err = c.Get(context.Background(), "/health", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("localhost:8000", "GET", "/health", "2xx")))
+	})
+}
+
+func TestMetrics_OnStateChange(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(reg, Options{})
+	assert.Nil(t, err)
+
+	m.OnStateChange("localhost:8000", httpClient.StateClosed, httpClient.StateOpen)
+	m.OnStateChange("localhost:8000", httpClient.StateOpen, httpClient.StateHalfOpen)
+	m.OnStateChange("localhost:8000", httpClient.StateHalfOpen, httpClient.StateOpen)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.circuit.WithLabelValues("localhost:8000", "open")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.circuit.WithLabelValues("localhost:8000", "half-open")))
+}
+
+func Test_statusClass(t *testing.T) {
+	assert.Equal(t, "error", statusClass(nil, context.Canceled))
+	assert.Equal(t, "error", statusClass(nil, nil))
+}
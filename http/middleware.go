@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+)
+
+// Doer send a single http request, *http.Client satisfies Doer
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapt ordinary function as Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wrap next Doer, it can inspect & mutate the outgoing request,
+// short-circuit by returning a response without calling next,
+// and post-process the response or error returned by next.
+//
+// Ordering: client middlewares (Client.Use) wrap request middlewares (Request.WithMiddlewares),
+// each in registration order, so the first registered middleware sees the request first
+// and the response last. Middlewares run once per attempt, inside retry, circuit breaker
+// & rate limiter.
+type Middleware func(next Doer) Doer
+
+// chain wrap doer with middlewares, first middleware become the outermost
+func chain(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
+
+// appendMiddlewares return new slice so shared client middlewares are never modified
+func appendMiddlewares(a []Middleware, b ...Middleware) []Middleware {
+	res := make([]Middleware, 0, len(a)+len(b))
+	res = append(res, a...)
+	return append(res, b...)
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func recordMiddleware(name string, calls *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, "before "+name)
+			resp, err := next.Do(req)
+			*calls = append(*calls, "after "+name)
+			return resp, err
+		})
+	}
+}
+
+func Test_clientImpl_Use(t *testing.T) {
+	t.Run("ordering", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/get").Reply(200)
+
+		var calls []string
+		c := &clientImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+		}
+		c.Use(recordMiddleware("client-1", &calls), recordMiddleware("client-2", &calls))
+
+		got, err := c.WithMiddlewares(recordMiddleware("request-1", &calls)).
+			WithMiddlewares(recordMiddleware("request-2", &calls)).
+			Get(context.Background(), "/get", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+		assert.Equal(t, []string{
+			"before client-1",
+			"before client-2",
+			"before request-1",
+			"before request-2",
+			"after request-2",
+			"after request-1",
+			"after client-2",
+			"after client-1",
+		}, calls)
+
+		// request middlewares must not leak into client
+		assert.Len(t, c.middlewares, 2)
+	})
+
+	t.Run("mutate request", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").
+			Get("/get").
+			MatchHeader("Authorization", "Bearer token").
+			Reply(200)
+
+		c := &clientImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+		}
+		c.Use(func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("Authorization", "Bearer token")
+				return next.Do(req)
+			})
+		})
+		got, err := c.Get(context.Background(), "/get", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("short-circuit", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/get").Reply(200)
+
+		c := &clientImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+		}
+		c.Use(func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusNotModified,
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			})
+		})
+		got, err := c.Get(context.Background(), "/get", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotModified, got.StatusCode)
+		assert.False(t, gock.IsDone())
+	})
+
+	t.Run("post-process error", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/get").Reply(500)
+
+		errServer := errors.New("server error")
+		c := &clientImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+		}
+		c.Use(func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.Do(req)
+				if err == nil && resp.StatusCode >= 500 {
+					drainBody(resp)
+					return nil, errServer
+				}
+				return resp, err
+			})
+		})
+		got, err := c.Get(context.Background(), "/get", nil)
+		assert.Nil(t, got)
+		assert.Equal(t, errServer, err)
+	})
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/cymon1997/go-client/http/codec"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// Multipart build multipart/form-data body, send it using Post or Put, e.g.
+//
+//	body := NewMultipart().
+//		Field("title", "report").
+//		File("file", "report.pdf", "application/pdf", f).
+//		JSON("meta", meta)
+//	resp, err := c.Post(ctx, "/upload", body)
+//
+// The body is streamed while sending, readers are consumed once per attempt.
+// Retries are only possible when every file reader is an io.Seeker, e.g. *os.File
+type Multipart struct {
+	boundary string
+	parts    []multipartPart
+	err      error
+}
+
+type multipartPart struct {
+	header textproto.MIMEHeader
+	value  []byte
+	reader io.Reader
+	offset int64
+}
+
+func NewMultipart() *Multipart {
+	return &Multipart{boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// Field add text field
+func (m *Multipart) Field(name, value string) *Multipart {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name)))
+	m.parts = append(m.parts, multipartPart{header: header, value: []byte(value)})
+	return m
+}
+
+// File add file read from r, empty contentType means application/octet-stream
+func (m *Multipart) File(name, filename, contentType string, r io.Reader) *Multipart {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(name), quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+
+	part := multipartPart{header: header, reader: r}
+	if s, ok := r.(io.Seeker); ok {
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil && m.err == nil {
+			m.err = fmt.Errorf("multipart: file %s: %w", name, err)
+		}
+		part.offset = offset
+	}
+	m.parts = append(m.parts, part)
+	return m
+}
+
+// JSON add part with v encoded as JSON, e.g. metadata of uploaded files
+func (m *Multipart) JSON(name string, v interface{}) *Multipart {
+	raw, err := codec.JSON.Marshal(v)
+	if err != nil && m.err == nil {
+		m.err = fmt.Errorf("multipart: json %s: %w", name, err)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name)))
+	header.Set("Content-Type", codec.JSON.ContentType())
+	m.parts = append(m.parts, multipartPart{header: header, value: raw})
+	return m
+}
+
+// ContentType return multipart/form-data content type including boundary
+func (m *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// stream return body streaming the parts
+func (m *Multipart) stream() (*streamBody, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	replayable := true
+	for _, p := range m.parts {
+		if _, ok := p.reader.(io.Seeker); p.reader != nil && !ok {
+			replayable = false
+		}
+	}
+	return &streamBody{
+		open: func() (io.ReadCloser, error) {
+			return newLazyPipe(m.writeTo), nil
+		},
+		size:        -1,
+		replayable:  replayable,
+		contentType: m.ContentType(),
+	}, nil
+}
+
+// writeTo write all parts, rewinding seekable readers
+func (m *Multipart) writeTo(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+	for _, p := range m.parts {
+		pw, err := mw.CreatePart(p.header)
+		if err != nil {
+			return err
+		}
+		if p.reader == nil {
+			if _, err = pw.Write(p.value); err != nil {
+				return err
+			}
+			continue
+		}
+		if s, ok := p.reader.(io.Seeker); ok {
+			if _, err = s.Seek(p.offset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		if _, err = io.Copy(pw, p.reader); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// lazyPipe stream output of write, write starts on first Read & Close wait for it to stop,
+// so bodies opened by GetBody never use the same readers concurrently
+type lazyPipe struct {
+	once  sync.Once
+	write func(w io.Writer) error
+	r     *io.PipeReader
+	w     *io.PipeWriter
+	done  chan struct{}
+}
+
+func newLazyPipe(write func(w io.Writer) error) *lazyPipe {
+	r, w := io.Pipe()
+	return &lazyPipe{write: write, r: r, w: w, done: make(chan struct{})}
+}
+
+func (p *lazyPipe) Read(b []byte) (int, error) {
+	p.once.Do(func() {
+		go func() {
+			defer close(p.done)
+			_ = p.w.CloseWithError(p.write(p.w))
+		}()
+	})
+	return p.r.Read(b)
+}
+
+func (p *lazyPipe) Close() error {
+	err := p.r.Close()
+	p.once.Do(func() { close(p.done) })
+	<-p.done
+	return err
+}
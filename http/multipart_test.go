@@ -0,0 +1,118 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type multipartResult struct {
+	fields      map[string]string
+	contentType map[string]string
+	filenames   map[string]string
+	chunked     bool
+}
+
+// newMultipartServer start server parsing multipart body, failing first failures attempts
+func newMultipartServer(t *testing.T, failures int32, result *multipartResult) (*httptest.Server, *int32) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mr, err := r.MultipartReader()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*result = multipartResult{
+			fields:      map[string]string{},
+			contentType: map[string]string{},
+			filenames:   map[string]string{},
+			chunked:     r.ContentLength == -1,
+		}
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			raw, _ := io.ReadAll(part)
+			result.fields[part.FormName()] = string(raw)
+			result.contentType[part.FormName()] = part.Header.Get("Content-Type")
+			result.filenames[part.FormName()] = part.FileName()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func TestMultipart(t *testing.T) {
+	var result multipartResult
+	srv, attempts := newMultipartServer(t, 1, &result)
+	c := New(Config{
+		Host:  srv.URL,
+		Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: 1, RetryNonIdempotent: true},
+	})
+
+	file := bytes.NewReader([]byte("%PDF-1.7 content"))
+	got, err := c.Post(context.Background(), "/upload", NewMultipart().
+		Field("title", `quarterly "report"`).
+		File("file", "report.pdf", "application/pdf", file).
+		File("raw", "data.bin", "", strings.NewReader("raw")).
+		JSON("meta", map[string]int{"pages": 3}))
+	assert.Nil(t, err)
+	assert.Equal(t, 201, got.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(attempts))
+	assert.Equal(t, map[string]string{
+		"title": `quarterly "report"`,
+		"file":  "%PDF-1.7 content",
+		"raw":   "raw",
+		"meta":  `{"pages":3}`,
+	}, result.fields)
+	assert.Equal(t, "application/pdf", result.contentType["file"])
+	assert.Equal(t, "application/octet-stream", result.contentType["raw"])
+	assert.Equal(t, "application/json", result.contentType["meta"])
+	assert.Equal(t, "report.pdf", result.filenames["file"])
+	assert.True(t, result.chunked)
+
+	// Case non-seekable reader is sent once
+	atomic.StoreInt32(attempts, 0)
+	got, err = c.Put(context.Background(), "/upload", NewMultipart().
+		File("file", "stream.txt", "text/plain", io.MultiReader(strings.NewReader("stream"))))
+	assert.Nil(t, err)
+	assert.Equal(t, 503, got.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(attempts))
+
+	// Case invalid JSON part
+	_, err = c.Post(context.Background(), "/upload", NewMultipart().JSON("meta", make(chan int)))
+	assert.ErrorContains(t, err, "multipart: json meta")
+}
+
+func TestMultipart_readerError(t *testing.T) {
+	var result multipartResult
+	srv, _ := newMultipartServer(t, 0, &result)
+	c := New(Config{Host: srv.URL})
+
+	errRead := errors.New("disk failure")
+	_, err := c.Post(context.Background(), "/upload", NewMultipart().
+		File("file", "broken.bin", "", io.MultiReader(strings.NewReader("partial"), errorReader{errRead})))
+	assert.ErrorIs(t, err, errRead)
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,242 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExpiryDelta refresh token before expiry to absorb clock skew & latency
+	defaultExpiryDelta = 10 * time.Second
+	// defaultTokenTimeout bound token requests so a hanging server does not block refresh forever
+	defaultTokenTimeout = 30 * time.Second
+)
+
+// OAuth2Config configure OAuth2 client credentials grant (RFC 6749 section 4.4)
+type OAuth2Config struct {
+	// TokenURL of authorization server token endpoint
+	TokenURL string
+	// ClientID & ClientSecret sent using HTTP Basic auth
+	ClientID     string
+	ClientSecret string
+	// CredentialsInBody send client credentials as form parameters instead of Basic auth
+	CredentialsInBody bool
+	// Scopes requested, nil means server default
+	Scopes []string
+	// Params additional form parameters, e.g. audience
+	Params map[string]string
+	// ExpiryDelta refresh token before it expires in milliseconds, default 10s
+	ExpiryDelta int
+	// TokenTimeout of a token request in milliseconds, default 30s
+	TokenTimeout int
+	// HTTPClient used for token requests, nil means http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// OAuth2 fetch & cache access tokens using client credentials grant,
+// safe for concurrent use. Only one token request is in flight at a time.
+type OAuth2 struct {
+	cfg     OAuth2Config
+	client  *http.Client
+	delta   time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expiry  time.Time
+	pending *tokenCall
+}
+
+// tokenCall is a token request shared by concurrent callers
+type tokenCall struct {
+	once  sync.Once
+	done  chan struct{}
+	token string
+	err   error
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func NewOAuth2(cfg OAuth2Config) *OAuth2 {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	delta := time.Duration(cfg.ExpiryDelta) * time.Millisecond
+	if delta <= 0 {
+		delta = defaultExpiryDelta
+	}
+	timeout := time.Duration(cfg.TokenTimeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTokenTimeout
+	}
+	return &OAuth2{
+		cfg:     cfg,
+		client:  client,
+		delta:   delta,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+// Authenticate set Authorization: Bearer header, use it as Config.Auth or Request.WithAuth.
+// Cached token is discarded when server respond 401 so retry fetch a new one
+func (o *OAuth2) Authenticate(req *http.Request) error {
+	token, err := o.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Middleware inject Authorization: Bearer header, register it using Client.Use.
+// Prefer Config.Auth which authenticates after other middlewares
+func (o *OAuth2) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return authenticate(next, o).Do(req.Clone(req.Context()))
+		})
+	}
+}
+
+// observe discard token rejected by server
+func (o *OAuth2) observe(req *http.Request, resp *http.Response) {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return
+	}
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		o.invalidate(strings.TrimPrefix(auth, "Bearer "))
+	}
+}
+
+// Token return cached access token, fetching new one when missing or about to expire.
+// Concurrent callers share the same token request
+func (o *OAuth2) Token(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	if o.token != "" && (o.expiry.IsZero() || o.now().Before(o.expiry.Add(-o.delta))) {
+		token := o.token
+		o.mu.Unlock()
+		return token, nil
+	}
+	call := o.pending
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		o.pending = call
+		// token request outlive the caller so other waiters are not failed by its cancellation
+		go o.refresh(withoutCancel{ctx}, call)
+	}
+	o.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// invalidate discard cached token if it is still the given one
+func (o *OAuth2) invalidate(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token == token {
+		o.token = ""
+	}
+}
+
+// refresh fetch token within TokenTimeout, the call is settled once the deadline
+// expires even if the HTTP client does not honor ctx
+func (o *OAuth2) refresh(ctx context.Context, call *tokenCall) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	stop := afterFunc(ctx, func() {
+		o.settle(call, "", 0, fmt.Errorf("oauth2: token request: %w", ctx.Err()))
+	})
+	token, expiresIn, err := o.fetch(ctx)
+	stop()
+	o.settle(call, token, expiresIn, err)
+}
+
+// settle store result of call & release its waiters, only the first result counts
+func (o *OAuth2) settle(call *tokenCall, token string, expiresIn time.Duration, err error) {
+	call.once.Do(func() {
+		o.mu.Lock()
+		if err == nil {
+			o.token = token
+			o.expiry = time.Time{}
+			if expiresIn > 0 {
+				o.expiry = o.now().Add(expiresIn)
+			}
+		}
+		if o.pending == call {
+			o.pending = nil
+		}
+		o.mu.Unlock()
+
+		call.token, call.err = token, err
+		close(call.done)
+	})
+}
+
+// fetch request new token from token endpoint
+func (o *OAuth2) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(o.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.cfg.Scopes, " "))
+	}
+	for k, v := range o.cfg.Params {
+		form.Set(k, v)
+	}
+	if o.cfg.CredentialsInBody {
+		form.Set("client_id", o.cfg.ClientID)
+		form.Set("client_secret", o.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("oauth2: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !o.cfg.CredentialsInBody {
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("oauth2: token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		httpErr, err := NewHTTPError(resp)
+		if err != nil {
+			return "", 0, fmt.Errorf("oauth2: token request: %w", err)
+		}
+		return "", 0, fmt.Errorf("oauth2: token request: %w", httpErr)
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", 0, fmt.Errorf("oauth2: decode token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", 0, errors.New("oauth2: token response without access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("oauth2: unsupported token type %q", token.TokenType)
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
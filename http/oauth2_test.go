@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTokenServer start token endpoint issuing sequential tokens, delay slow down every response
+func newTokenServer(t *testing.T, expiresIn int, delay time.Duration) (*httptest.Server, *int32) {
+	var issued int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		time.Sleep(delay)
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d-%s","token_type":"Bearer","expires_in":%d}`,
+			n, r.FormValue("scope"), expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &issued
+}
+
+func TestOAuth2_Authenticate(t *testing.T) {
+	tokenSrv, issued := newTokenServer(t, 3600, 0)
+	var authorization []string
+	var mu sync.Mutex
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.URL.Path == "/revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	auth := NewOAuth2(OAuth2Config{
+		TokenURL:     tokenSrv.URL,
+		ClientID:     "client",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"read", "write"},
+	})
+	c := New(Config{Host: api.URL, Timeout: 3000, Auth: auth})
+
+	for i := 0; i < 3; i++ {
+		got, err := c.Get(context.Background(), "/get", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(issued))
+
+	// Case 401 discard cached token
+	got, err := c.Get(context.Background(), "/revoked", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 401, got.StatusCode)
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(issued))
+	assert.Equal(t, []string{
+		"Bearer token-1-read write",
+		"Bearer token-1-read write",
+		"Bearer token-1-read write",
+		"Bearer token-1-read write",
+		"Bearer token-2-read write",
+	}, authorization)
+}
+
+func TestOAuth2_Token(t *testing.T) {
+	// Case concurrent callers share single token request
+	tokenSrv, issued := newTokenServer(t, 3600, 50*time.Millisecond)
+	auth := NewOAuth2(OAuth2Config{
+		TokenURL:     tokenSrv.URL,
+		ClientID:     "client",
+		ClientSecret: "s3cret",
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := auth.Token(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, "token-1-", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(issued))
+
+	// Case refresh shortly before expiry
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+	auth.mu.Lock()
+	auth.expiry = now.Add(5 * time.Second)
+	auth.mu.Unlock()
+	token, err := auth.Token(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2-", token)
+
+	// Case caller cancelled while waiting, token still cached for others
+	auth.invalidate(token)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = auth.Token(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	token, err = auth.Token(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token-3-", token)
+	assert.Equal(t, int32(3), atomic.LoadInt32(issued))
+
+	// Case credentials rejected
+	auth = NewOAuth2(OAuth2Config{
+		TokenURL:          tokenSrv.URL,
+		ClientID:          "client",
+		ClientSecret:      "s3cret",
+		CredentialsInBody: true,
+	})
+	_, err = auth.Token(context.Background())
+	assert.True(t, IsUnauthorized(err))
+	var httpErr *HTTPError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, `{"error":"invalid_client"}`, string(httpErr.Body))
+	}
+
+	c := New(Config{Host: tokenSrv.URL})
+	c.Use(auth.Middleware())
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.True(t, IsUnauthorized(err))
+}
+
+func TestOAuth2_Token_hanging(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// first token request hang until released
+		if atomic.AddInt32(&requests, 1) == 1 {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	auth := NewOAuth2(OAuth2Config{
+		TokenURL:     srv.URL,
+		ClientID:     "client",
+		ClientSecret: "s3cret",
+		TokenTimeout: 100,
+	})
+	_, err := auth.Token(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// pending request is cleared after TokenTimeout, next caller send a new one
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	token, err := auth.Token(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", token)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+)
+
+// Option customize client built by New
+type Option func(c *clientImpl)
+
+// WithTransport use rt to send requests instead of transport built from Config.Transport,
+// client given by WithHTTPClient is copied so it is left untouched
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *clientImpl) {
+		client := *c.client
+		client.Transport = rt
+		c.client = &client
+	}
+}
+
+// WithHTTPClient use client as is to send requests,
+// Config.Timeout, Config.Transport, Config.TLS & Config.Proxy are ignored
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *clientImpl) {
+		c.client = client
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestWithTransport(t *testing.T) {
+	rt := &recordTransport{}
+	c := New(Config{
+		Host:    "http://localhost:8000",
+		Timeout: 3000,
+		Transport: TransportConfig{
+			MaxIdleConnsPerHost: 10,
+		},
+	}, WithTransport(rt))
+
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Len(t, rt.requests, 1)
+	assert.Equal(t, "http://localhost:8000/get", rt.requests[0].URL.String())
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	rt := &recordTransport{}
+	client := &http.Client{Transport: rt}
+	c := New(Config{
+		Host:    "http://localhost:8000",
+		Timeout: 3000,
+	}, WithHTTPClient(client))
+	assert.Equal(t, client, c.(*clientImpl).client)
+
+	got, err := c.Delete(context.Background(), "/delete/1")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Len(t, rt.requests, 1)
+
+	// Case shared client is not mutated by WithTransport
+	other := &recordTransport{}
+	c = New(Config{Host: "http://localhost:8000"}, WithHTTPClient(client), WithTransport(other))
+	_, err = c.Delete(context.Background(), "/delete/1")
+	assert.Nil(t, err)
+	assert.Equal(t, rt, client.Transport)
+	assert.Len(t, rt.requests, 1)
+	assert.Len(t, other.requests, 1)
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// proxyLog record requests seen by test proxies
+type proxyLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *proxyLog) add(entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *proxyLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.entries...)
+}
+
+// newTestProxy start HTTP proxy answering requests itself,
+// recording request URI & Proxy-Authorization header it received
+func newTestProxy(t *testing.T) (*httptest.Server, *proxyLog) {
+	log := &proxyLog{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.add(r.RequestURI + " " + r.Header.Get("Proxy-Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, log
+}
+
+// newTestSOCKS5 start SOCKS5 proxy without authentication connecting every request to target
+func newTestSOCKS5(t *testing.T, target string) (string, *proxyLog) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	log := &proxyLog{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			addr, err := socks5Handshake(conn)
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+			log.add(addr)
+			upstream, err := net.Dial("tcp", target)
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+			go func() { _, _ = io.Copy(upstream, conn) }()
+			go func() { _, _ = io.Copy(conn, upstream) }()
+		}
+	}()
+	return ln.Addr().String(), log
+}
+
+// socks5Handshake serve greeting & CONNECT command, returning requested address
+func socks5Handshake(conn net.Conn) (string, error) {
+	buf := make([]byte, 262)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return "", err
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+			return "", err
+		}
+		host = net.IP(buf[:4]).String()
+	case 3:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return "", err
+		}
+		n := int(buf[0])
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return "", err
+		}
+		host = string(buf[:n])
+	default:
+		return "", io.ErrUnexpectedEOF
+	}
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return "", err
+	}
+	port := binary.BigEndian.Uint16(buf[:2])
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+func TestProxyConfig_URL(t *testing.T) {
+	proxy, received := newTestProxy(t)
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "secret")
+
+	c := New(Config{
+		Host:    "http://sample.internal",
+		Timeout: 3000,
+		Proxy: &ProxyConfig{
+			URL:     proxyURL.String(),
+			NoProxy: "bypass.internal",
+		},
+	})
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Equal(t, []string{"http://sample.internal/get Basic dXNlcjpzZWNyZXQ="}, received.list())
+
+	// Case host in NoProxy connect directly
+	c = New(Config{
+		Host:    "http://bypass.internal",
+		Timeout: 3000,
+		Proxy: &ProxyConfig{
+			URL:     proxy.URL,
+			NoProxy: "bypass.internal",
+		},
+	})
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.Error(t, err)
+	assert.Len(t, received.list(), 1)
+}
+
+func TestProxyConfig_environment(t *testing.T) {
+	proxy, received := newTestProxy(t)
+	t.Setenv("HTTP_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "bypass.internal")
+
+	c := New(Config{
+		Host:    "http://sample.internal",
+		Timeout: 3000,
+		Proxy:   &ProxyConfig{NoProxy: "other.internal"},
+	})
+	_, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Len(t, received.list(), 1)
+
+	for _, host := range []string{"http://bypass.internal", "http://other.internal"} {
+		c = New(Config{
+			Host:    host,
+			Timeout: 3000,
+			Proxy:   &ProxyConfig{NoProxy: "other.internal"},
+		})
+		_, err = c.Get(context.Background(), "/get", nil)
+		assert.Error(t, err)
+	}
+	assert.Len(t, received.list(), 1)
+}
+
+func TestProxyConfig_selector(t *testing.T) {
+	proxy, received := newTestProxy(t)
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	c := New(Config{
+		Host:    "http://sample.internal",
+		Timeout: 3000,
+		Proxy: &ProxyConfig{
+			URL: "http://unused.internal:3128",
+			Selector: func(req *http.Request) (*url.URL, error) {
+				if req.URL.Path == "/direct" {
+					return nil, nil
+				}
+				return proxyURL, nil
+			},
+		},
+	})
+	_, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	_, err = c.Get(context.Background(), "/direct", nil)
+	assert.Error(t, err)
+	assert.Len(t, received.list(), 1)
+}
+
+func TestProxyConfig_SOCKS5(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	addr, requested := newTestSOCKS5(t, srv.Listener.Addr().String())
+
+	c := New(Config{
+		Host:    "http://sample.internal:8080",
+		Timeout: 3000,
+		Proxy:   &ProxyConfig{URL: "socks5://" + addr},
+	})
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 202, got.StatusCode)
+	assert.Equal(t, []string{"sample.internal:8080"}, requested.list())
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval between removals of idle per endpoint & per key buckets
+const sweepInterval = time.Minute
+
+// ErrRateLimited returned when a request is not allowed by the client-side rate limiter,
+// either in fail-fast mode or when waiting would exceed the context deadline
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitConfig configure client-side token bucket rate limiting,
+// a request must be allowed by every configured limit
+type RateLimitConfig struct {
+	// Global limit shared by all requests, nil means unlimited
+	Global *Limit
+	// PerEndpoint limit per host & endpoint template, nil means unlimited
+	PerEndpoint *Limit
+	// PerKey limit per key set using WithRateLimitKey, nil means unlimited
+	PerKey *Limit
+	// FailFast return ErrRateLimited instead of waiting for a token
+	FailFast bool
+}
+
+// Limit of token bucket
+type Limit struct {
+	// Rate of requests per second, zero or negative means unlimited
+	Rate float64
+	// Burst max requests at once, default 1
+	Burst int
+}
+
+// limited check whether l actually limit requests
+func (l *Limit) limited() bool {
+	return l != nil && l.Rate > 0
+}
+
+type rateLimitKey struct{}
+
+// WithRateLimitKey return ctx carrying key used by RateLimitConfig.PerKey, e.g. tenant ID
+func WithRateLimitKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, key)
+}
+
+// rateLimiter hold buckets shared by all requests of a client, per endpoint & per key
+// buckets refilled to burst are removed since a new bucket behaves the same
+type rateLimiter struct {
+	cfg      RateLimitConfig
+	global   *bucket
+	mu       sync.Mutex
+	endpoint map[string]*bucket
+	key      map[string]*bucket
+	swept    time.Time
+	now      func() time.Time
+}
+
+func newRateLimiter(cfg *RateLimitConfig) *rateLimiter {
+	if cfg == nil {
+		return nil
+	}
+	l := &rateLimiter{
+		cfg:      *cfg,
+		endpoint: make(map[string]*bucket),
+		key:      make(map[string]*bucket),
+		now:      time.Now,
+	}
+	// unlimited per endpoint & per key limits would only fill the maps with nil buckets
+	if !cfg.PerEndpoint.limited() {
+		l.cfg.PerEndpoint = nil
+	}
+	if !cfg.PerKey.limited() {
+		l.cfg.PerKey = nil
+	}
+	l.global = newBucket(cfg.Global, l.now())
+	l.swept = l.now()
+	return l
+}
+
+// wait block until all applicable buckets allow the request or ctx is done
+func (l *rateLimiter) wait(ctx context.Context, endpoint string) error {
+	if l == nil {
+		return nil
+	}
+	now := l.now()
+	buckets, delay := l.reserve(ctx, endpoint, now)
+	if delay <= 0 {
+		return nil
+	}
+
+	cancel := func() {
+		for _, b := range buckets {
+			b.cancel()
+		}
+	}
+	if l.cfg.FailFast {
+		cancel()
+		return ErrRateLimited
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+		cancel()
+		return ErrRateLimited
+	}
+	if err := sleep(ctx, delay); err != nil {
+		cancel()
+		return err
+	}
+	return nil
+}
+
+// reserve take a token from all applicable buckets & return the longest wait,
+// tokens are reserved under l.mu so a bucket is never swept in between
+func (l *rateLimiter) reserve(ctx context.Context, endpoint string, now time.Time) ([]*bucket, time.Duration) {
+	buckets := make([]*bucket, 0, 3)
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	if l.cfg.PerEndpoint != nil {
+		buckets = append(buckets, l.bucket(l.endpoint, endpoint, l.cfg.PerEndpoint))
+	}
+	if key, ok := ctx.Value(rateLimitKey{}).(string); ok && l.cfg.PerKey != nil {
+		buckets = append(buckets, l.bucket(l.key, key, l.cfg.PerKey))
+	}
+
+	var delay time.Duration
+	for _, b := range buckets {
+		if d := b.reserve(now); d > delay {
+			delay = d
+		}
+	}
+	return buckets, delay
+}
+
+// sweep remove idle buckets refilled to burst, at most once per sweepInterval, l.mu must be held
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepInterval {
+		return
+	}
+	l.swept = now
+	for _, buckets := range []map[string]*bucket{l.endpoint, l.key} {
+		for k, b := range buckets {
+			if b.full(now) {
+				delete(buckets, k)
+			}
+		}
+	}
+}
+
+func (l *rateLimiter) bucket(buckets map[string]*bucket, key string, limit *Limit) *bucket {
+	b, ok := buckets[key]
+	if !ok {
+		b = newBucket(limit, l.now())
+		buckets[key] = b
+	}
+	return b
+}
+
+// bucket is a token bucket, tokens may go negative to queue reservations
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit *Limit, now time.Time) *bucket {
+	if !limit.limited() {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// reserve take a token and return how long to wait before it is available
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// full check whether bucket is refilled to burst at now
+func (b *bucket) full(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// cancel give back a reserved token
+func (b *bucket) cancel() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func Test_bucket_reserve(t *testing.T) {
+	now := time.Now()
+	b := newBucket(&Limit{Rate: 10, Burst: 2}, now)
+
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, 100*time.Millisecond, b.reserve(now))
+	b.cancel()
+
+	// refilled but never above burst
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, 100*time.Millisecond, b.reserve(now))
+
+	assert.Nil(t, newBucket(nil, now))
+	assert.Nil(t, newBucket(&Limit{}, now))
+}
+
+func Test_rateLimiter_wait(t *testing.T) {
+	t.Run("fail fast", func(t *testing.T) {
+		l := newRateLimiter(&RateLimitConfig{
+			Global:      &Limit{Rate: 1, Burst: 2},
+			PerEndpoint: &Limit{Rate: 1, Burst: 1},
+			FailFast:    true,
+		})
+		ctx := context.Background()
+		assert.Nil(t, l.wait(ctx, "localhost/a"))
+		assert.True(t, errors.Is(l.wait(ctx, "localhost/a"), ErrRateLimited))
+		// rejected request must not consume global token
+		assert.Nil(t, l.wait(ctx, "localhost/b"))
+	})
+
+	t.Run("per key", func(t *testing.T) {
+		l := newRateLimiter(&RateLimitConfig{
+			PerKey:   &Limit{Rate: 1, Burst: 1},
+			FailFast: true,
+		})
+		tenantA := WithRateLimitKey(context.Background(), "tenant-a")
+		tenantB := WithRateLimitKey(context.Background(), "tenant-b")
+		assert.Nil(t, l.wait(tenantA, "localhost/a"))
+		assert.True(t, errors.Is(l.wait(tenantA, "localhost/a"), ErrRateLimited))
+		assert.Nil(t, l.wait(tenantB, "localhost/a"))
+		// request without key is not limited by PerKey
+		assert.Nil(t, l.wait(context.Background(), "localhost/a"))
+	})
+
+	t.Run("block until allowed", func(t *testing.T) {
+		l := newRateLimiter(&RateLimitConfig{
+			Global: &Limit{Rate: 50, Burst: 1},
+		})
+		ctx := context.Background()
+		start := time.Now()
+		assert.Nil(t, l.wait(ctx, "localhost/a"))
+		assert.Nil(t, l.wait(ctx, "localhost/a"))
+		assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+	})
+
+	t.Run("respect ctx while waiting", func(t *testing.T) {
+		l := newRateLimiter(&RateLimitConfig{
+			Global: &Limit{Rate: 0.1, Burst: 1},
+		})
+		assert.Nil(t, l.wait(context.Background(), "localhost/a"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		assert.True(t, errors.Is(l.wait(ctx, "localhost/a"), context.Canceled))
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.True(t, errors.Is(l.wait(ctx, "localhost/a"), ErrRateLimited))
+	})
+}
+
+func Test_rateLimiter_sweep(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(&RateLimitConfig{
+		PerEndpoint: &Limit{Rate: 1, Burst: 2},
+		PerKey:      &Limit{Rate: 1, Burst: 1},
+		FailFast:    true,
+	})
+	l.now = func() time.Time { return now }
+	l.swept = now
+
+	for i := 0; i < 100; i++ {
+		ctx := WithRateLimitKey(context.Background(), fmt.Sprintf("tenant-%d", i))
+		assert.Nil(t, l.wait(ctx, fmt.Sprintf("localhost/users/%d", i)))
+	}
+	assert.Equal(t, 100, len(l.endpoint))
+	assert.Equal(t, 100, len(l.key))
+
+	// tenant-0 keep being limited, others idle & refilled
+	now = now.Add(sweepInterval)
+	tenant := WithRateLimitKey(context.Background(), "tenant-0")
+	assert.Nil(t, l.wait(tenant, "localhost/users/0"))
+	assert.True(t, errors.Is(l.wait(tenant, "localhost/users/0"), ErrRateLimited))
+	assert.Equal(t, 1, len(l.endpoint))
+	assert.Equal(t, 1, len(l.key))
+
+	// bucket not yet refilled is kept on next sweep
+	now = now.Add(sweepInterval - time.Nanosecond)
+	assert.Nil(t, l.wait(context.Background(), "localhost/users/1"))
+	assert.Equal(t, 1, len(l.key))
+	now = now.Add(time.Nanosecond)
+	assert.Nil(t, l.wait(context.Background(), "localhost/users/2"))
+	assert.Equal(t, 0, len(l.key))
+}
+
+func Test_rateLimiter_sweepUnlimited(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(&RateLimitConfig{
+		PerEndpoint: &Limit{},
+		PerKey:      &Limit{Rate: 0, Burst: 1},
+	})
+	l.now = func() time.Time { return now }
+	l.swept = now
+
+	ctx := WithRateLimitKey(context.Background(), "tenant-a")
+	assert.Nil(t, l.wait(ctx, "localhost/a"))
+	now = now.Add(sweepInterval)
+	assert.Nil(t, l.wait(ctx, "localhost/a"))
+	assert.Equal(t, 0, len(l.endpoint))
+	assert.Equal(t, 0, len(l.key))
+
+	// nil bucket is always full
+	assert.True(t, (*bucket)(nil).full(now))
+}
+
+func Test_requestImpl_exec_rateLimit(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("http://localhost:8000").Get("/get").Reply(200)
+
+	c := &clientImpl{
+		client:  &http.Client{},
+		baseURL: "http://localhost:8000",
+		limiter: newRateLimiter(&RateLimitConfig{
+			Global:   &Limit{Rate: 1, Burst: 1},
+			FailFast: true,
+		}),
+	}
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+
+	got, err = c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, got)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+}
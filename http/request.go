@@ -3,10 +3,14 @@ package http
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/cymon1997/go-client/http/codec"
 	"github.com/cymon1997/go-client/http/util"
 	"github.com/cymon1997/go-client/internal/utils"
 	"github.com/google/go-querystring/query"
@@ -15,6 +19,11 @@ import (
 type Request interface {
 	WithHeaders(headers map[string]string) Request
 	WithCookies(cookies []*http.Cookie) Request
+	WithRetry(policy *RetryPolicy) Request
+	WithPathParams(params map[string]string) Request
+	WithMiddlewares(middlewares ...Middleware) Request
+	WithAuth(auth Authenticator) Request
+	WithCodec(c codec.Codec) Request
 
 	Get(ctx context.Context, endpoint string, params interface{}) (*http.Response, error)
 	Post(ctx context.Context, endpoint string, body interface{}) (*http.Response, error)
@@ -23,15 +32,45 @@ type Request interface {
 	PutRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error)
 	Patch(ctx context.Context, endpoint string, body interface{}) (*http.Response, error)
 	PatchRaw(ctx context.Context, endpoint string, raw []byte) (*http.Response, error)
+	PostStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error)
+	PutStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error)
+	PatchStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error)
 	Delete(ctx context.Context, endpoint string) (*http.Response, error)
+
+	Subscribe(ctx context.Context, endpoint string, handler func(Event) error) error
+	Events(ctx context.Context, endpoint string) (<-chan Event, <-chan error)
+	Dial(ctx context.Context, endpoint string) (*WebSocket, error)
 }
 
 type requestImpl struct {
-	client  *http.Client
-	baseURL string
-	body    []byte
-	headers map[string]string
-	cookies []*http.Cookie
+	client      *http.Client
+	baseURL     string
+	body        []byte
+	query       string
+	headers     map[string]string
+	cookies     []*http.Cookie
+	pathParams  map[string]string
+	retry       *RetryPolicy
+	breakers    *circuitBreakers
+	limiter     *rateLimiter
+	middlewares []Middleware
+	httpError   bool
+	logger      *requestLogger
+	auth        Authenticator
+	codec       codec.Codec
+	contentType string
+	stream      *streamBody
+	websocket   *WebSocketConfig
+}
+
+// streamBody is request body read from a stream instead of body bytes
+type streamBody struct {
+	open func() (io.ReadCloser, error)
+	// size of body, -1 means unknown & sent chunked
+	size int64
+	// replayable whether open can be called again for retry & GetBody
+	replayable  bool
+	contentType string
 }
 
 func NewRequest(client *http.Client, baseURL string, headers map[string]string) Request {
@@ -52,11 +91,42 @@ func (r *requestImpl) WithCookies(cookies []*http.Cookie) Request {
 	return r
 }
 
+// WithRetry override retry policy for this request, nil means no retry
+func (r *requestImpl) WithRetry(policy *RetryPolicy) Request {
+	r.retry = policy
+	return r
+}
+
+// WithPathParams set values of endpoint placeholders, e.g. {id} in /users/{id},
+// the unexpanded endpoint is used as endpoint template
+func (r *requestImpl) WithPathParams(params map[string]string) Request {
+	r.pathParams = utils.CombineMapString(r.pathParams, params, utils.MergeReplace)
+	return r
+}
+
+// WithMiddlewares add middlewares for this request, they run inside client middlewares
+func (r *requestImpl) WithMiddlewares(middlewares ...Middleware) Request {
+	r.middlewares = appendMiddlewares(r.middlewares, middlewares...)
+	return r
+}
+
+// WithAuth override authenticator for this request, nil means no authentication
+func (r *requestImpl) WithAuth(auth Authenticator) Request {
+	r.auth = auth
+	return r
+}
+
+// WithCodec override codec encoding body of this request & sent as Accept, nil means codec.JSON
+func (r *requestImpl) WithCodec(c codec.Codec) Request {
+	r.codec = c
+	return r
+}
+
 // Get used for retrieve a resource
 func (r *requestImpl) Get(ctx context.Context, endpoint string, params interface{}) (*http.Response, error) {
 	v, _ := query.Values(params)
-	fmt.Println("DEBUG: ", v.Encode())
-	return r.exec(ctx, http.MethodGet, fmt.Sprintf("%s?%s", endpoint, v.Encode()))
+	r.query = v.Encode()
+	return r.exec(ctx, http.MethodGet, endpoint)
 }
 
 // Post used for create a resource
@@ -89,16 +159,42 @@ func (r *requestImpl) PatchRaw(ctx context.Context, endpoint string, raw []byte)
 	return r.execRaw(ctx, http.MethodPatch, endpoint, raw)
 }
 
+// PostStream is streaming version of PostRaw, body is read while sending instead of loaded
+// into memory, size <= 0 means unknown length sent using chunked encoding, nil body sends no body.
+// Retries & redirects are only possible when body is an io.Seeker, e.g. *os.File
+func (r *requestImpl) PostStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	return r.execReader(ctx, http.MethodPost, endpoint, body, size)
+}
+
+// PutStream is streaming version of PutRaw, see PostStream
+func (r *requestImpl) PutStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	return r.execReader(ctx, http.MethodPut, endpoint, body, size)
+}
+
+// PatchStream is streaming version of PatchRaw, see PostStream
+func (r *requestImpl) PatchStream(ctx context.Context, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	return r.execReader(ctx, http.MethodPatch, endpoint, body, size)
+}
+
 // Delete used for delete a resource
 func (r *requestImpl) Delete(ctx context.Context, endpoint string) (*http.Response, error) {
 	return r.exec(ctx, http.MethodDelete, endpoint)
 }
 
 func (r *requestImpl) execBody(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
-	raw, err := json.Marshal(body)
+	if m, ok := body.(*Multipart); ok {
+		stream, err := m.stream()
+		if err != nil {
+			return nil, err
+		}
+		return r.execStream(ctx, method, endpoint, stream)
+	}
+	c := r.bodyCodec()
+	raw, err := c.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
+	r.contentType = c.ContentType()
 	return r.execRaw(ctx, method, endpoint, raw)
 }
 
@@ -107,16 +203,116 @@ func (r *requestImpl) execRaw(ctx context.Context, method, endpoint string, raw
 	return r.exec(ctx, method, endpoint)
 }
 
-func (r *requestImpl) exec(ctx context.Context, method, uri string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprint(r.baseURL, uri), bytes.NewBuffer(r.body))
+func (r *requestImpl) execReader(ctx context.Context, method, endpoint string, body io.Reader, size int64) (*http.Response, error) {
+	stream, err := newReaderStream(body, size)
 	if err != nil {
 		return nil, err
 	}
-	util.SetHeaders(req, r.headers)
-	util.SetCookies(req, r.cookies)
-	resp, err := r.client.Do(req)
+	return r.execStream(ctx, method, endpoint, stream)
+}
+
+func (r *requestImpl) execStream(ctx context.Context, method, endpoint string, stream *streamBody) (*http.Response, error) {
+	r.stream = stream
+	r.contentType = stream.contentType
+	return r.exec(ctx, method, endpoint)
+}
+
+func (r *requestImpl) exec(ctx context.Context, method, endpoint string) (*http.Response, error) {
+	resp, err := r.execAttempts(ctx, method, endpoint)
+	if err != nil || !r.httpError || util.IsStatusOK(resp) {
+		return resp, err
+	}
+	httpErr, err := NewHTTPError(resp)
+	if err != nil {
+		return resp, err
+	}
+	return resp, httpErr
+}
+
+// execAttempts send request with rate limiting, circuit breaker & retry
+func (r *requestImpl) execAttempts(ctx context.Context, method, endpoint string) (*http.Response, error) {
+	target, err := url.Parse(r.buildURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	key := r.breakers.key(target.Host, endpoint, len(r.pathParams) > 0)
+	doer := chain(authenticate(r.logger.wrap(r.client), r.auth), r.middlewares)
+	attempts := r.retry.attempts(method)
+	if r.stream != nil && !r.stream.replayable {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		if err = r.limiter.wait(ctx, target.Host+endpoint); err != nil {
+			return nil, err
+		}
+		done, err := r.breakers.allow(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		resp, err := r.do(withRequestInfo(ctx, endpoint, attempt, len(r.pathParams) > 0), doer, method, target)
+		done(resp, err)
+		if attempt >= attempts || ctx.Err() != nil || !r.retry.shouldRetry(resp, err) {
+			return resp, err
+		}
+		delay := r.retry.Backoff(attempt)
+		if !fitDeadline(ctx, delay, time.Since(start)) {
+			return resp, err
+		}
+		drainBody(resp)
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do send a single attempt, body is re-read from r.body or reopened from r.stream so it can be replayed
+func (r *requestImpl) do(ctx context.Context, doer Doer, method string, target *url.URL) (*http.Response, error) {
+	var body io.Reader = bytes.NewReader(r.body)
+	if r.stream != nil {
+		rc, err := r.stream.open()
+		if err != nil {
+			return nil, err
+		}
+		body = rc
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
 	if err != nil {
+		if rc, ok := body.(io.Closer); ok {
+			_ = rc.Close()
+		}
 		return nil, err
 	}
-	return resp, nil
+	if r.stream != nil {
+		req.ContentLength = r.stream.size
+		if r.stream.replayable {
+			req.GetBody = r.stream.open
+		}
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	req.Header.Set("Accept", r.bodyCodec().ContentType())
+	util.SetHeaders(req, r.headers)
+	util.SetCookies(req, r.cookies)
+	return doer.Do(req)
+}
+
+// bodyCodec return codec of request, JSON by default
+func (r *requestImpl) bodyCodec() codec.Codec {
+	if r.codec == nil {
+		return codec.JSON
+	}
+	return r.codec
+}
+
+// buildURL expand endpoint template with path params & append query
+func (r *requestImpl) buildURL(endpoint string) string {
+	for k, v := range r.pathParams {
+		endpoint = strings.ReplaceAll(endpoint, "{"+k+"}", url.PathEscape(v))
+	}
+	if r.query == "" {
+		return fmt.Sprint(r.baseURL, endpoint)
+	}
+	return fmt.Sprint(r.baseURL, endpoint, "?", r.query)
 }
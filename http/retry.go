@@ -0,0 +1,179 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	RetryOnTimeout ErrorClass = iota
+	RetryOnConnectionReset
+	RetryOnConnectionRefused
+	RetryOnUnexpectedEOF
+)
+
+// ErrorClass classify transport error to decide whether it should be retried
+type ErrorClass int
+
+var (
+	// DefaultRetryStatusCodes used when RetryPolicy.RetryStatusCodes is nil
+	DefaultRetryStatusCodes = []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+	// DefaultRetryErrors used when RetryPolicy.RetryErrors is nil
+	DefaultRetryErrors = []ErrorClass{
+		RetryOnTimeout,
+		RetryOnConnectionReset,
+		RetryOnConnectionRefused,
+		RetryOnUnexpectedEOF,
+	}
+)
+
+// RetryPolicy decide how a failed request should be retried,
+// backoff is exponential with full jitter: random(0, min(MaxDelay, BaseDelay * 2^retry))
+type RetryPolicy struct {
+	// MaxAttempts including the first attempt, <= 1 means no retry
+	MaxAttempts int
+	// BaseDelay of backoff in milliseconds
+	BaseDelay int
+	// MaxDelay of backoff in milliseconds, 0 means no limit
+	MaxDelay int
+	// RetryStatusCodes response status codes to retry, nil means DefaultRetryStatusCodes
+	RetryStatusCodes []int
+	// RetryErrors transport error classes to retry, nil means DefaultRetryErrors
+	RetryErrors []ErrorClass
+	// RetryNonIdempotent allow retrying non-idempotent methods (POST & PATCH)
+	RetryNonIdempotent bool
+}
+
+// attempts return max attempts allowed for the method
+func (p *RetryPolicy) attempts(method string) int {
+	if p == nil || p.MaxAttempts <= 1 {
+		return 1
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(method) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry check whether the result of an attempt is retryable
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		class, ok := classifyError(err)
+		if !ok {
+			return false
+		}
+		classes := p.RetryErrors
+		if classes == nil {
+			classes = DefaultRetryErrors
+		}
+		for _, c := range classes {
+			if c == class {
+				return true
+			}
+		}
+		return false
+	}
+	if resp == nil {
+		return false
+	}
+	codes := p.RetryStatusCodes
+	if codes == nil {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if code == resp.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff return delay before the n-th retry (start from 1)
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	ceil := time.Duration(p.BaseDelay) * time.Millisecond
+	maxDelay := time.Duration(p.MaxDelay) * time.Millisecond
+	for i := 1; i < retry && ceil < math.MaxInt64/2; i++ {
+		ceil *= 2
+	}
+	if maxDelay > 0 && ceil > maxDelay {
+		ceil = maxDelay
+	}
+	if ceil <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceil) + 1))
+}
+
+// fitDeadline check whether another attempt could finish before ctx deadline,
+// assuming the next attempt takes as long as the previous one
+func fitDeadline(ctx context.Context, delay, last time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+	return time.Until(deadline) > delay+last
+}
+
+// sleep wait for delay or until ctx is done
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drainBody discard & close response body so the connection can be reused
+func drainBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPatch:
+		return false
+	default:
+		return true
+	}
+}
+
+func classifyError(err error) (ErrorClass, bool) {
+	if errors.Is(err, context.Canceled) {
+		return 0, false
+	}
+	switch {
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return RetryOnConnectionReset, true
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return RetryOnConnectionRefused, true
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return RetryOnUnexpectedEOF, true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return RetryOnTimeout, true
+	}
+	return 0, false
+}
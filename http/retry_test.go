@@ -0,0 +1,219 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestRetryPolicy_attempts(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		method string
+		want   int
+	}{
+		{
+			name:   "case nil policy",
+			policy: nil,
+			method: http.MethodGet,
+			want:   1,
+		},
+		{
+			name:   "case idempotent",
+			policy: &RetryPolicy{MaxAttempts: 3},
+			method: http.MethodPut,
+			want:   3,
+		},
+		{
+			name:   "case non-idempotent without opt in",
+			policy: &RetryPolicy{MaxAttempts: 3},
+			method: http.MethodPost,
+			want:   1,
+		},
+		{
+			name:   "case non-idempotent with opt in",
+			policy: &RetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true},
+			method: http.MethodPatch,
+			want:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.attempts(tt.method))
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 10, MaxDelay: 50}
+	for retry, ceil := range map[int]time.Duration{
+		1:   10 * time.Millisecond,
+		2:   20 * time.Millisecond,
+		3:   40 * time.Millisecond,
+		4:   50 * time.Millisecond,
+		100: 50 * time.Millisecond,
+	} {
+		for i := 0; i < 50; i++ {
+			got := p.Backoff(retry)
+			assert.GreaterOrEqual(t, got, time.Duration(0))
+			assert.LessOrEqual(t, got, ceil)
+		}
+	}
+	assert.Equal(t, time.Duration(0), (&RetryPolicy{}).Backoff(1))
+}
+
+func Test_classifyError(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		want  ErrorClass
+		retry bool
+	}{
+		{
+			name:  "case connection reset",
+			err:   fmt.Errorf("read: %w", syscall.ECONNRESET),
+			want:  RetryOnConnectionReset,
+			retry: true,
+		},
+		{
+			name:  "case connection refused",
+			err:   fmt.Errorf("dial: %w", syscall.ECONNREFUSED),
+			want:  RetryOnConnectionRefused,
+			retry: true,
+		},
+		{
+			name:  "case unexpected eof",
+			err:   io.ErrUnexpectedEOF,
+			want:  RetryOnUnexpectedEOF,
+			retry: true,
+		},
+		{
+			name:  "case timeout",
+			err:   context.DeadlineExceeded,
+			want:  RetryOnTimeout,
+			retry: true,
+		},
+		{
+			name: "case canceled",
+			err:  context.Canceled,
+		},
+		{
+			name: "case unknown",
+			err:  errors.New("unknown"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := classifyError(tt.err)
+			assert.Equal(t, tt.retry, ok)
+			if ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_fitDeadline(t *testing.T) {
+	assert.True(t, fitDeadline(context.Background(), time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.True(t, fitDeadline(ctx, 10*time.Millisecond, 10*time.Millisecond))
+	assert.False(t, fitDeadline(ctx, 800*time.Millisecond, 300*time.Millisecond))
+}
+
+func Test_requestImpl_exec_retry(t *testing.T) {
+	t.Run("retry status code until success", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/get").Times(2).Reply(503)
+		gock.New("http://localhost:8000").Get("/get").Reply(200)
+
+		r := &requestImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+			retry:   &RetryPolicy{MaxAttempts: 3, BaseDelay: 1},
+		}
+		got, err := r.exec(context.Background(), http.MethodGet, "/get")
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("return last response when attempts exhausted", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/get").Times(2).Reply(502)
+
+		r := &requestImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+			retry:   &RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+		}
+		got, err := r.exec(context.Background(), http.MethodGet, "/get")
+		assert.Nil(t, err)
+		assert.Equal(t, 502, got.StatusCode)
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("retry transport error", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Get("/get").ReplyError(syscall.ECONNRESET)
+		gock.New("http://localhost:8000").Get("/get").Reply(200)
+
+		r := &requestImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+			retry:   &RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+		}
+		got, err := r.exec(context.Background(), http.MethodGet, "/get")
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+	})
+
+	t.Run("skip non-idempotent without opt in", func(t *testing.T) {
+		defer gock.OffAll()
+
+		gock.New("http://localhost:8000").Post("/post").Reply(503)
+		gock.New("http://localhost:8000").Post("/post").Reply(200)
+
+		r := &requestImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+			retry:   &RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+		}
+		got, err := r.PostRaw(context.Background(), "/post", []byte(`{}`))
+		assert.Nil(t, err)
+		assert.Equal(t, 503, got.StatusCode)
+		assert.False(t, gock.IsDone())
+	})
+
+	t.Run("replay body on each attempt", func(t *testing.T) {
+		defer gock.OffAll()
+
+		raw := []byte(`{"data":"sample data"}`)
+		gock.New("http://localhost:8000").Post("/post").Body(bytes.NewReader(raw)).Reply(503)
+		gock.New("http://localhost:8000").Post("/post").Body(bytes.NewReader(raw)).Reply(200)
+
+		r := &requestImpl{
+			client:  &http.Client{},
+			baseURL: "http://localhost:8000",
+			retry:   &RetryPolicy{MaxAttempts: 2, BaseDelay: 1, RetryNonIdempotent: true},
+		}
+		got, err := r.PostRaw(context.Background(), "/post", raw)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+		assert.True(t, gock.IsDone())
+	})
+}
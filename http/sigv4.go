@@ -0,0 +1,205 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// sigV4IgnoredHeaders are not signed since proxies & transport may change them
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"connection":      true,
+}
+
+// SigV4Config configure AWS Signature Version 4 signing
+type SigV4Config struct {
+	// Region e.g. us-east-1
+	Region string
+	// Service signing name e.g. execute-api, es, aoss, s3
+	Service string
+	// Credentials provider, e.g. StaticCredentials, EnvCredentials or ProfileCredentials
+	Credentials CredentialsProvider
+	// UnsignedPayload skip hashing body, used for streamed bodies where supported by the service
+	UnsignedPayload bool
+	// DisableURIPathEscaping use escaped path as is without normalization, required by S3
+	DisableURIPathEscaping bool
+}
+
+// SigV4Signer sign requests using AWS Signature Version 4, use it as Config.Auth or Request.WithAuth
+type SigV4Signer struct {
+	cfg SigV4Config
+	now func() time.Time
+}
+
+func NewSigV4Signer(cfg SigV4Config) *SigV4Signer {
+	return &SigV4Signer{cfg: cfg, now: time.Now}
+}
+
+// Authenticate set X-Amz-Date, X-Amz-Security-Token & Authorization headers
+func (s *SigV4Signer) Authenticate(req *http.Request) error {
+	creds, err := s.cfg.Credentials.Retrieve(req.Context())
+	if err != nil {
+		return fmt.Errorf("sigv4: %w", err)
+	}
+	payloadHash, err := s.payloadHash(req)
+	if err != nil {
+		return fmt.Errorf("sigv4: read body: %w", err)
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if s.cfg.UnsignedPayload || s.cfg.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := s.canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format("20060102"), s.cfg.Region, s.cfg.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, s.cfg.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+func (s *SigV4Signer) payloadHash(req *http.Request) (string, error) {
+	if s.cfg.UnsignedPayload {
+		return unsignedPayload, nil
+	}
+	return bodyHash(req)
+}
+
+// canonicalHeaders return signed header names & canonical headers block
+func (s *SigV4Signer) canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{"host": canonicalHost(req)}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if sigV4IgnoredHeaders[name] {
+			continue
+		}
+		trimmed := make([]string, len(v))
+		for i := range v {
+			trimmed[i] = strings.Join(strings.Fields(v[i]), " ")
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(values[name])
+		b.WriteByte('\n')
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// canonicalURI normalize & URI-encode path again, except when escaping is disabled
+func (s *SigV4Signer) canonicalURI(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	if s.cfg.DisableURIPathEscaping {
+		return p
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return uriEncode(cleaned, false)
+}
+
+// canonicalQuery sort query params by key then value, encoded per SigV4
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	params := make([]string, 0, len(query))
+	for k, values := range query {
+		for _, v := range values {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// canonicalHost return host header without default port
+func canonicalHost(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	if h, port, err := net.SplitHostPort(host); err == nil &&
+		(port == "80" && req.URL.Scheme == "http" || port == "443" && req.URL.Scheme == "https") {
+		return h
+	}
+	return host
+}
+
+// uriEncode percent-encode all bytes except unreserved characters, and slash unless encodeSlash
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cymon1997/go-client/http/util"
+	"github.com/cymon1997/go-client/internal/utils"
+)
+
+// maxEventLine limit length of a single line of event stream
+const maxEventLine = 1 << 20
+
+// defaultReconnect backoff used when Request has no retry policy,
+// unlimited reconnects starting from 1s up to 30s
+var defaultReconnect = &RetryPolicy{BaseDelay: 1000, MaxDelay: 30000}
+
+// minReconnectDelay between reconnects, so a server closing connections right away
+// is not hammered when retry policy has no BaseDelay
+var minReconnectDelay = time.Second
+
+// reconnectDelay raise delay to minReconnectDelay
+func reconnectDelay(delay time.Duration) time.Duration {
+	if delay < minReconnectDelay {
+		return minReconnectDelay
+	}
+	return delay
+}
+
+// Event is a message of Server-Sent Events stream (text/event-stream)
+type Event struct {
+	// ID of the last event received, sent as Last-Event-ID on reconnect
+	ID string
+	// Event type, "message" when the server does not set it
+	Event string
+	// Data lines joined by newline
+	Data string
+	// Retry reconnection time requested by server in milliseconds, 0 when not set
+	Retry int
+}
+
+// Subscribe connect to Server-Sent Events endpoint & call handler for every event until ctx is done
+// or handler return error. Connection is re-established with Last-Event-ID when it drops, waiting
+// for reconnection time sent by server or backoff of retry policy but at least 1s, where MaxAttempts
+// (if set) limit consecutive failed connections. Client timeout does not apply to the stream.
+//
+// Subscribe return nil when server respond 204 No Content, *HTTPError on non-retryable status,
+// ctx error when ctx is done or error returned by handler
+func (r *requestImpl) Subscribe(ctx context.Context, endpoint string, handler func(Event) error) error {
+	policy := r.retry
+	if policy == nil {
+		policy = defaultReconnect
+	}
+	var lastID string
+	var reconnect time.Duration
+	for failures := 0; ; {
+		resp, err := r.connectEvents(ctx, endpoint, lastID)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err == nil && resp.StatusCode == http.StatusNoContent:
+			drainBody(resp)
+			return nil
+		case err == nil && util.IsStatusOK(resp):
+			failures = 0
+			err = readEvents(ctx, resp, &lastID, &reconnect, handler)
+			var stop *handlerError
+			if errors.As(err, &stop) {
+				return stop.err
+			}
+		case err == nil:
+			httpErr, herr := NewHTTPError(resp)
+			if herr != nil {
+				return herr
+			}
+			if !IsRetryable(httpErr) {
+				return httpErr
+			}
+			err = httpErr
+			failures++
+		default:
+			failures++
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if policy.MaxAttempts > 0 && failures >= policy.MaxAttempts {
+			return err
+		}
+
+		delay := reconnect
+		if delay == 0 || failures > 0 {
+			delay = policy.Backoff(failures + 1)
+		}
+		if err := sleep(ctx, reconnectDelay(delay)); err != nil {
+			return err
+		}
+	}
+}
+
+// Events is channel version of Subscribe, events channel is closed when subscription ends
+// & its result is sent to the buffered errors channel. Stop it by cancelling ctx
+func (r *requestImpl) Events(ctx context.Context, endpoint string) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(events)
+		errs <- r.Subscribe(ctx, endpoint, func(e Event) error {
+			select {
+			case events <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events, errs
+}
+
+// connectEvents send a single request for event stream, bypassing client timeout & retry
+func (r *requestImpl) connectEvents(ctx context.Context, endpoint, lastID string) (*http.Response, error) {
+	conn := *r
+	client := *r.client
+	client.Timeout = 0
+	conn.client = &client
+	conn.retry = nil
+	conn.httpError = false
+	headers := map[string]string{
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+	if lastID != "" {
+		headers["Last-Event-ID"] = lastID
+	}
+	conn.headers = utils.CombineMapString(r.headers, headers, utils.MergeReplace)
+	return conn.exec(ctx, http.MethodGet, endpoint)
+}
+
+// handlerError wrap error returned by Subscribe handler
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+// readEvents parse event stream until it ends, updating last event ID & reconnection time
+func readEvents(ctx context.Context, resp *http.Response, lastID *string, reconnect *time.Duration,
+	handler func(Event) error) error {
+	defer resp.Body.Close()
+	stop := afterFunc(ctx, func() { _ = resp.Body.Close() })
+	defer stop()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 4096), maxEventLine)
+	scanner.Split(scanEventLines)
+
+	var event Event
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data.Len() > 0 {
+				event.ID = *lastID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				if err := handler(event); err != nil {
+					return &handlerError{err: err}
+				}
+			}
+			event = Event{}
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				*lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 && strings.Trim(value, "0123456789") == "" {
+				event.Retry = ms
+				*reconnect = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// scanEventLines split lines ending with CRLF, LF or CR
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// CR may be followed by LF in the next read
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+	if atEOF && len(data) > 0 {
+		// incomplete line at end of stream is discarded with the incomplete event
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_readEvents(t *testing.T) {
+	stream := ": keep-alive\n" +
+		"event: progress\ndata: {\"percent\":10}\nid: 1\n\n" +
+		"data: line 1\r\ndata:line 2\r\n\r\n" +
+		"retry: 2500\rid\r\r" +
+		"id: bad\x00id\nevent: ignored without data\n\n" +
+		"data\n\n" +
+		"data: incomplete"
+	resp := &http.Response{Body: io.NopCloser(strings.NewReader(stream))}
+
+	var events []Event
+	lastID := "0"
+	var reconnect time.Duration
+	err := readEvents(context.Background(), resp, &lastID, &reconnect, func(e Event) error {
+		events = append(events, e)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Event{
+		{ID: "1", Event: "progress", Data: `{"percent":10}`},
+		{ID: "1", Event: "message", Data: "line 1\nline 2"},
+		{ID: "", Event: "message", Data: ""},
+	}, events)
+	assert.Equal(t, "", lastID)
+	assert.Equal(t, 2500*time.Millisecond, reconnect)
+}
+
+// fastReconnect lower minReconnectDelay until the test ends
+func fastReconnect(t *testing.T) {
+	delay := minReconnectDelay
+	minReconnectDelay = time.Millisecond
+	t.Cleanup(func() { minReconnectDelay = delay })
+}
+
+func TestRequest_Subscribe(t *testing.T) {
+	fastReconnect(t)
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connections, 1)
+		if r.Header.Get("Authorization") != "Bearer abc" || r.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch n {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "retry: 10\n\nid: 1\ndata: first\n\nid: 2\ndata: second\n\n")
+		case 2:
+			// server temporarily unavailable
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprintf(w, "data: resumed after %s\n\n", r.Header.Get("Last-Event-ID"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(Config{Host: srv.URL, Timeout: 3000, Auth: BearerToken("abc")})
+	var data []string
+	err := c.WithRetry(&RetryPolicy{BaseDelay: 1, MaxDelay: 5}).
+		Subscribe(context.Background(), "/events", func(e Event) error {
+			data = append(data, e.ID+":"+e.Data)
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1:first", "2:second", "2:resumed after 2"}, data)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&connections))
+
+	// Case handler error stop subscription
+	atomic.StoreInt32(&connections, 0)
+	errStop := errors.New("stop")
+	err = c.Subscribe(context.Background(), "/events", func(e Event) error {
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	// Case non-retryable status
+	err = New(Config{Host: srv.URL}).Subscribe(context.Background(), "/events", func(e Event) error {
+		return nil
+	})
+	assert.True(t, IsUnauthorized(err))
+}
+
+func TestRequest_Subscribe_maxAttempts(t *testing.T) {
+	fastReconnect(t)
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := New(Config{Host: srv.URL, Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: 1}})
+	err := c.Subscribe(context.Background(), "/events", func(e Event) error { return nil })
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&connections))
+}
+
+func TestRequest_Subscribe_minReconnectDelay(t *testing.T) {
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// stream ending right away reset failures, so backoff stay at its zero BaseDelay
+		atomic.AddInt32(&connections, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	c := New(Config{Host: srv.URL, Retry: &RetryPolicy{MaxAttempts: 3}})
+	err := c.Subscribe(ctx, "/events", func(e Event) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&connections))
+}
+
+func TestRequest_Events(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; ; i++ {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: tick\ndata: %d\n\n", i, i); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	}))
+	defer srv.Close()
+
+	// client timeout does not cut the stream
+	c := New(Config{Host: srv.URL, Timeout: 30})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := c.Events(ctx, "/events")
+
+	var got []Event
+	for e := range events {
+		got = append(got, e)
+		if len(got) == 3 {
+			cancel()
+		}
+	}
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	assert.Equal(t, Event{ID: "3", Event: "tick", Data: "3"}, got[2])
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// errStreamConsumed returned when a non-seekable stream is opened again, e.g. by redirect
+var errStreamConsumed = errors.New("http: request body stream already consumed")
+
+// newReaderStream create body streamed from r, size <= 0 means unknown length sent chunked
+// and nil r means no body. Seekable readers are rewound to their current offset for every attempt
+func newReaderStream(r io.Reader, size int64) (*streamBody, error) {
+	if r == nil {
+		return &streamBody{
+			open:       func() (io.ReadCloser, error) { return http.NoBody, nil },
+			replayable: true,
+		}, nil
+	}
+	if size <= 0 {
+		size = -1
+	}
+	if s, ok := r.(io.ReadSeeker); ok {
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		return &streamBody{
+			open: func() (io.ReadCloser, error) {
+				return &rewindReader{r: s, offset: offset}, nil
+			},
+			size:       size,
+			replayable: true,
+		}, nil
+	}
+
+	var once sync.Once
+	return &streamBody{
+		open: func() (io.ReadCloser, error) {
+			err := errStreamConsumed
+			once.Do(func() { err = nil })
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(r), nil
+		},
+		size: size,
+	}, nil
+}
+
+// rewindReader seek to offset on first Read, so bodies opened by GetBody
+// are read one after another from the same source
+type rewindReader struct {
+	r       io.ReadSeeker
+	offset  int64
+	started bool
+}
+
+func (r *rewindReader) Read(b []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		if _, err := r.r.Seek(r.offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+	return r.r.Read(b)
+}
+
+func (r *rewindReader) Close() error {
+	return nil
+}
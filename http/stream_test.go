@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type streamReceived struct {
+	body          string
+	contentLength int64
+	chunked       bool
+}
+
+// newStreamServer record received bodies, /flaky fail first attempt & /redirect redirect with 307
+func newStreamServer(t *testing.T) (*httptest.Server, func() []streamReceived) {
+	var mu sync.Mutex
+	var received []streamReceived
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, streamReceived{
+			body:          string(raw),
+			contentLength: r.ContentLength,
+			chunked:       len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked",
+		})
+		n := len(received)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/flaky" && n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/redirect":
+			http.Redirect(w, r, "/target", http.StatusTemporaryRedirect)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []streamReceived {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]streamReceived(nil), received...)
+	}
+}
+
+func TestRequest_PostStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c Client) (*http.Response, error)
+		status   int
+		received []streamReceived
+	}{
+		{
+			name: "known size",
+			call: func(c Client) (*http.Response, error) {
+				return c.PostStream(context.Background(), "/upload", io.MultiReader(strings.NewReader("export")), 6)
+			},
+			status:   200,
+			received: []streamReceived{{body: "export", contentLength: 6}},
+		},
+		{
+			name: "unknown size is chunked",
+			call: func(c Client) (*http.Response, error) {
+				return c.PutStream(context.Background(), "/upload", io.MultiReader(strings.NewReader("export")), -1)
+			},
+			status:   200,
+			received: []streamReceived{{body: "export", contentLength: -1, chunked: true}},
+		},
+		{
+			name: "zero size is chunked",
+			call: func(c Client) (*http.Response, error) {
+				return c.PatchStream(context.Background(), "/upload", strings.NewReader("export"), 0)
+			},
+			status:   200,
+			received: []streamReceived{{body: "export", contentLength: -1, chunked: true}},
+		},
+		{
+			name: "nil body",
+			call: func(c Client) (*http.Response, error) {
+				return c.PatchStream(context.Background(), "/upload", nil, 0)
+			},
+			status:   200,
+			received: []streamReceived{{contentLength: 0}},
+		},
+		{
+			name: "seekable retried from current offset",
+			call: func(c Client) (*http.Response, error) {
+				body := strings.NewReader("header:export")
+				_, _ = body.Seek(7, io.SeekStart)
+				return c.PostStream(context.Background(), "/flaky", body, 6)
+			},
+			status: 200,
+			received: []streamReceived{
+				{body: "export", contentLength: 6},
+				{body: "export", contentLength: 6},
+			},
+		},
+		{
+			name: "seekable follow redirect",
+			call: func(c Client) (*http.Response, error) {
+				return c.PostStream(context.Background(), "/redirect", strings.NewReader("export"), -1)
+			},
+			status: 200,
+			received: []streamReceived{
+				{body: "export", contentLength: -1, chunked: true},
+				{body: "export", contentLength: -1, chunked: true},
+			},
+		},
+		{
+			name: "non-seekable not retried",
+			call: func(c Client) (*http.Response, error) {
+				return c.PostStream(context.Background(), "/flaky", io.MultiReader(strings.NewReader("export")), -1)
+			},
+			status:   503,
+			received: []streamReceived{{body: "export", contentLength: -1, chunked: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := newStreamServer(t)
+			c := New(Config{
+				Host:  srv.URL,
+				Retry: &RetryPolicy{MaxAttempts: 2, BaseDelay: 1, RetryNonIdempotent: true},
+			})
+			got, err := tt.call(c)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.status, got.StatusCode)
+			assert.Equal(t, tt.received, received())
+		})
+	}
+}
+
+func TestRequest_PostStream_signed(t *testing.T) {
+	srv := newHMACServer(t, "s3cret", time.Time{})
+	c := New(Config{
+		Host: srv.URL,
+		Auth: NewHMACSigner(HMACConfig{KeyID: "partner", Secret: []byte("s3cret")}),
+	})
+
+	got, err := c.PostStream(context.Background(), "/payments", strings.NewReader(`{"amount": 100}`), -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+
+	got, err = c.PostStream(context.Background(), "/payments", io.MultiReader(strings.NewReader(`{}`)), -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultReloadInterval between checks of certificate files for changes
+const defaultReloadInterval = 10 * time.Second
+
+// TLSConfig configure server verification & client certificate (mutual TLS),
+// certificates loaded from files are reloaded automatically when the files change
+type TLSConfig struct {
+	// CAFile path of PEM encoded CA bundle trusted to verify server
+	CAFile string
+	// CAPEM PEM encoded CA bundle, used when CAFile is empty
+	CAPEM []byte
+	// CertFile & KeyFile path of PEM encoded client certificate pair
+	CertFile string
+	KeyFile  string
+	// CertPEM & KeyPEM PEM encoded client certificate pair, used when CertFile is empty
+	CertPEM []byte
+	KeyPEM  []byte
+	// MinVersion e.g. tls.VersionTLS13, 0 means tls.VersionTLS12
+	MinVersion uint16
+	// CipherSuites allowed for TLS 1.2, nil means Go defaults
+	CipherSuites []uint16
+	// ServerName override SNI & host name used to verify server certificate
+	ServerName string
+	// ReloadInterval between checks of certificate files for changes in milliseconds, default 10s
+	ReloadInterval int
+}
+
+// Build load certificates & return tls.Config, files are read once,
+// http.Client reload them on change by itself
+func (cfg *TLSConfig) Build() (*tls.Config, error) {
+	tc := &tls.Config{
+		MinVersion:   cfg.MinVersion,
+		CipherSuites: cfg.CipherSuites,
+		ServerName:   cfg.ServerName,
+	}
+	if tc.MinVersion == 0 {
+		tc.MinVersion = tls.VersionTLS12
+	}
+
+	ca, err := readPEM(cfg.CAFile, cfg.CAPEM)
+	if err != nil {
+		return nil, fmt.Errorf("tls: read CA: %w", err)
+	}
+	if ca != nil {
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, errors.New("tls: no valid CA certificate found")
+		}
+	}
+
+	cert, err := readPEM(cfg.CertFile, cfg.CertPEM)
+	if err != nil {
+		return nil, fmt.Errorf("tls: read client certificate: %w", err)
+	}
+	key, err := readPEM(cfg.KeyFile, cfg.KeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("tls: read client key: %w", err)
+	}
+	if cert != nil || key != nil {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{pair}
+	}
+	return tc, nil
+}
+
+// files return configured certificate files
+func (cfg *TLSConfig) files() []string {
+	var files []string
+	for _, f := range []string{cfg.CAFile, cfg.CertFile, cfg.KeyFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func readPEM(file string, pem []byte) ([]byte, error) {
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return pem, nil
+}
+
+// newTLSTransport apply cfg to transport, errors are returned on every request
+// since New never fails
+func newTLSTransport(transport *http.Transport, cfg TLSConfig) http.RoundTripper {
+	if files := cfg.files(); len(files) > 0 {
+		interval := time.Duration(cfg.ReloadInterval) * time.Millisecond
+		if interval <= 0 {
+			interval = defaultReloadInterval
+		}
+		return &reloadingTransport{
+			base:     transport,
+			cfg:      cfg,
+			files:    files,
+			interval: interval,
+		}
+	}
+	tc, err := cfg.Build()
+	if err != nil {
+		return failingTransport{err: err}
+	}
+	transport.TLSClientConfig = tc
+	return transport
+}
+
+// reloadingTransport rebuild the underlying transport when certificate files change,
+// connections of the previous transport are closed once idle
+type reloadingTransport struct {
+	base     *http.Transport
+	cfg      TLSConfig
+	files    []string
+	interval time.Duration
+
+	mu      sync.Mutex
+	current *http.Transport
+	stamp   string
+	checked time.Time
+}
+
+func (t *reloadingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, err := t.transport()
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+func (t *reloadingTransport) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != nil {
+		t.current.CloseIdleConnections()
+	}
+}
+
+// transport return current transport, reloading certificates when files changed.
+// When reload fails the previous transport is kept, e.g. files partially written
+func (t *reloadingTransport) transport() (*http.Transport, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if t.current != nil && now.Sub(t.checked) < t.interval {
+		return t.current, nil
+	}
+	t.checked = now
+
+	stamp, err := fileStamp(t.files)
+	if err == nil && t.current != nil && stamp == t.stamp {
+		return t.current, nil
+	}
+	var tc *tls.Config
+	if err == nil {
+		tc, err = t.cfg.Build()
+	}
+	if err != nil {
+		if t.current != nil {
+			return t.current, nil
+		}
+		return nil, err
+	}
+
+	next := t.base.Clone()
+	next.TLSClientConfig = tc
+	if t.current != nil {
+		t.current.CloseIdleConnections()
+	}
+	t.current, t.stamp = next, stamp
+	return next, nil
+}
+
+// fileStamp summarize size & modification time of files
+func fileStamp(files []string) (string, error) {
+	var stamp string
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", f, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
+
+// failingTransport report configuration error on every request
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	closeBody(req)
+	return nil, t.err
+}
+
+// closeBody close request body as required by http.RoundTripper on error
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,237 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	pair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	assert.Nil(t, err)
+	return pair
+}
+
+// newTestCert create certificate signed by parent, nil parent means self-signed CA
+func newTestCert(t *testing.T, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "go-client test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost", "sample.internal"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func newMutualTLSServer(t *testing.T, ca *testCert) *httptest.Server {
+	server := newTestCert(t, ca, x509.ExtKeyUsageServerAuth)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{server.tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	srv.StartTLS()
+	return srv
+}
+
+func TestTLSConfig_CAPEM(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	// Case unknown authority
+	c := New(Config{Host: srv.URL, Timeout: 3000})
+	_, err := c.Get(context.Background(), "/get", nil)
+	assert.Error(t, err)
+
+	// Case trusted CA
+	c = New(Config{
+		Host:    srv.URL,
+		Timeout: 3000,
+		TLS: &TLSConfig{
+			CAPEM:      caPEM,
+			MinVersion: tls.VersionTLS13,
+		},
+	})
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Equal(t, uint16(tls.VersionTLS13), got.TLS.Version)
+
+	// Case server name override, httptest certificate is valid for example.com
+	c = New(Config{
+		Host:    srv.URL,
+		Timeout: 3000,
+		TLS: &TLSConfig{
+			CAPEM:      caPEM,
+			ServerName: "example.com",
+		},
+	})
+	got, err = c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com", got.TLS.ServerName)
+
+	c = New(Config{
+		Host:    srv.URL,
+		Timeout: 3000,
+		TLS: &TLSConfig{
+			CAPEM:      caPEM,
+			ServerName: "invalid.example.org",
+		},
+	})
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.Error(t, err)
+
+	// Case invalid CA
+	c = New(Config{
+		Host: srv.URL,
+		TLS:  &TLSConfig{CAPEM: []byte("invalid")},
+	})
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.ErrorContains(t, err, "no valid CA certificate found")
+}
+
+func TestTLSConfig_mutualTLS(t *testing.T) {
+	ca := newTestCert(t, nil, x509.ExtKeyUsageAny)
+	client := newTestCert(t, ca, x509.ExtKeyUsageClientAuth)
+	srv := newMutualTLSServer(t, ca)
+	defer srv.Close()
+
+	// Case no client certificate
+	c := New(Config{
+		Host:    srv.URL,
+		Timeout: 3000,
+		TLS:     &TLSConfig{CAPEM: ca.certPEM},
+	})
+	_, err := c.Get(context.Background(), "/get", nil)
+	assert.Error(t, err)
+
+	// Case client certificate
+	c = New(Config{
+		Host:    srv.URL,
+		Timeout: 3000,
+		TLS: &TLSConfig{
+			CAPEM:   ca.certPEM,
+			CertPEM: client.certPEM,
+			KeyPEM:  client.keyPEM,
+		},
+	})
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+}
+
+func TestTLSConfig_reload(t *testing.T) {
+	ca := newTestCert(t, nil, x509.ExtKeyUsageAny)
+	srv := newMutualTLSServer(t, ca)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+
+	// client certificate signed by other CA is rejected by server
+	other := newTestCert(t, nil, x509.ExtKeyUsageAny)
+	client := newTestCert(t, other, x509.ExtKeyUsageClientAuth)
+	assert.Nil(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+	assert.Nil(t, os.WriteFile(certFile, client.certPEM, 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, client.keyPEM, 0o600))
+
+	c := New(Config{
+		Host:    srv.URL,
+		Timeout: 3000,
+		TLS: &TLSConfig{
+			CAFile:         caFile,
+			CertFile:       certFile,
+			KeyFile:        keyFile,
+			ReloadInterval: 1,
+		},
+	})
+	_, err := c.Get(context.Background(), "/get", nil)
+	assert.Error(t, err)
+
+	// rotate client certificate on disk
+	client = newTestCert(t, ca, x509.ExtKeyUsageClientAuth)
+	assert.Nil(t, os.WriteFile(certFile, client.certPEM, 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, client.keyPEM, 0o600))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(certFile, future, future))
+	time.Sleep(5 * time.Millisecond)
+
+	got, err := c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+
+	// broken file keep previous certificates
+	assert.Nil(t, os.WriteFile(keyFile, []byte("partial"), 0o600))
+	time.Sleep(5 * time.Millisecond)
+	got, err = c.Get(context.Background(), "/get", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+
+	// missing file on first load is reported
+	c = New(Config{
+		Host: srv.URL,
+		TLS:  &TLSConfig{CAFile: filepath.Join(dir, "missing.pem")},
+	})
+	_, err = c.Get(context.Background(), "/get", nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,130 @@
+// Package tracing provide OpenTelemetry instrumentation for http.Client
+package tracing
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/cymon1997/go-client/http/tracing"
+
+type config struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// Option configure tracing middleware
+type Option func(cfg *config)
+
+// WithTracerProvider set tracer provider, default: otel.GetTracerProvider()
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.provider = provider
+	}
+}
+
+// WithPropagators set propagator used to inject headers, default: W3C trace context
+func WithPropagators(propagator propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		cfg.propagator = propagator
+	}
+}
+
+// Middleware start a client span for every request sent by the client,
+// inject trace context headers (traceparent & tracestate) from the request ctx
+// and record status code or error.
+//
+// Following HTTP semantic conventions, each retry attempt is its own span
+// with http.request.resend_count set, so register it using Client.Use.
+func Middleware(opts ...Option) httpClient.Middleware {
+	cfg := &config{
+		provider:   otel.GetTracerProvider(),
+		propagator: propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	tracer := cfg.provider.Tracer(instrumentationName)
+
+	return func(next httpClient.Doer) httpClient.Doer {
+		return httpClient.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			template := endpointTemplate(req)
+			ctx, span := tracer.Start(req.Context(), spanName(req.Method, template),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attributes(req, template)...),
+			)
+			defer span.End()
+
+			req = req.Clone(ctx)
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.Do(req)
+			if err != nil {
+				span.SetAttributes(semconv.ErrorType(err))
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+			return resp, nil
+		})
+	}
+}
+
+// endpointTemplate return endpoint template, empty when path params are not set
+// since the raw endpoint may contain IDs & must not be used as span name
+func endpointTemplate(req *http.Request) string {
+	if !httpClient.HasPathParams(req.Context()) {
+		return ""
+	}
+	return httpClient.EndpointTemplate(req.Context())
+}
+
+func spanName(method, template string) string {
+	if template == "" {
+		return method
+	}
+	return method + " " + template
+}
+
+func attributes(req *http.Request, template string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(fullURL(req.URL)),
+		semconv.ServerAddress(req.URL.Hostname()),
+	}
+	if port := req.URL.Port(); port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(p))
+		}
+	}
+	if template != "" {
+		attrs = append(attrs, semconv.URLTemplate(template))
+	}
+	if attempt := httpClient.Attempt(req.Context()); attempt > 1 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(attempt-1))
+	}
+	return attrs
+}
+
+// fullURL strip credentials & query which may carry secrets
+func fullURL(u *url.URL) string {
+	res := *u
+	res.User = nil
+	res.RawQuery = ""
+	res.ForceQuery = false
+	return res.String()
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	httpClient "github.com/cymon1997/go-client/http"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func attributeMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	res := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, attr := range attrs {
+		res[attr.Key] = attr.Value
+	}
+	return res
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("tracing.Middleware", func(t *testing.T) {
+		defer gock.OffAll()
+
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		c := httpClient.New(httpClient.Config{
+			Host:    "http://localhost:8000",
+			Timeout: 3000,
+			Retry:   &httpClient.RetryPolicy{MaxAttempts: 2, BaseDelay: 1},
+		})
+		c.Use(Middleware(WithTracerProvider(provider)))
+
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+		traceID := parent.SpanContext().TraceID().String()
+
+		gock.New("http://localhost:8000").
+			Get("/users/1").
+			MatchHeader("Traceparent", "^00-"+traceID+"-[0-9a-f]{16}-01$").
+			Reply(503)
+		gock.New("http://localhost:8000").
+			Get("/users/1").
+			MatchHeader("Traceparent", "^00-"+traceID+"-[0-9a-f]{16}-01$").
+			Reply(200)
+
+		got, err := c.WithPathParams(map[string]string{"id": "1"}).
+			Get(ctx, "/users/{id}", struct {
+				Token string `url:"token"`
+			}{Token: "secret"})
+		parent.End()
+		assert.Nil(t, err)
+		assert.Equal(t, 200, got.StatusCode)
+		assert.True(t, gock.IsDone())
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 3)
+
+		first, second := spans[0], spans[1]
+		for _, span := range []tracetest.SpanStub{first, second} {
+			assert.Equal(t, "GET /users/{id}", span.Name)
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		}
+
+		attrs := attributeMap(first.Attributes)
+		assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+		assert.Equal(t, "http://localhost:8000/users/1", attrs["url.full"].AsString())
+		assert.Equal(t, "/users/{id}", attrs["url.template"].AsString())
+		assert.Equal(t, "localhost", attrs["server.address"].AsString())
+		assert.Equal(t, int64(8000), attrs["server.port"].AsInt64())
+		assert.Equal(t, int64(503), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, "503", attrs["error.type"].AsString())
+		assert.Equal(t, codes.Error, first.Status.Code)
+		_, ok := attrs["http.request.resend_count"]
+		assert.False(t, ok)
+
+		attrs = attributeMap(second.Attributes)
+		assert.Equal(t, int64(200), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, int64(1), attrs["http.request.resend_count"].AsInt64())
+		assert.Equal(t, codes.Unset, second.Status.Code)
+	})
+
+	t.Run("record error", func(t *testing.T) {
+		defer gock.OffAll()
+
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		c := httpClient.New(httpClient.Config{Host: "http://localhost:8000"})
+		c.Use(Middleware(WithTracerProvider(provider)))
+
+		gock.New("http://localhost:8000").
+			Delete("/delete/1").
+			ReplyError(http.ErrHandlerTimeout)
+
+		_, err := c.Delete(context.Background(), "/delete/1")
+		assert.Error(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		// raw endpoint without path params is not a template
+		assert.Equal(t, "DELETE", spans[0].Name)
+		_, ok := attributeMap(spans[0].Attributes)["url.template"]
+		assert.False(t, ok)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Len(t, spans[0].Events, 1)
+		assert.Equal(t, "exception", spans[0].Events[0].Name)
+	})
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tune connection pooling & timeouts of the underlying transport,
+// zero value keep http.DefaultTransport
+type TransportConfig struct {
+	// MaxIdleConns across all hosts, 0 means default (100)
+	MaxIdleConns int
+	// MaxIdleConnsPerHost, 0 means default (2)
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost including active connections, 0 means no limit
+	MaxConnsPerHost int
+	// IdleConnTimeout in milliseconds, 0 means default (90s)
+	IdleConnTimeout int
+	// DialTimeout in milliseconds, 0 means default (30s)
+	DialTimeout int
+	// KeepAlive interval of TCP keep-alive probes in milliseconds, 0 means default (30s)
+	KeepAlive int
+	// TLSHandshakeTimeout in milliseconds, 0 means default (10s)
+	TLSHandshakeTimeout int
+	// ResponseHeaderTimeout in milliseconds, 0 means no timeout
+	ResponseHeaderTimeout int
+	// DisableKeepAlives use a new connection for every request
+	DisableKeepAlives bool
+}
+
+// newTransport build transport from cfg, nil means http.DefaultTransport
+func newTransport(cfg Config) http.RoundTripper {
+	if cfg.Transport == (TransportConfig{}) && cfg.TLS == nil && cfg.Proxy == nil {
+		return nil
+	}
+	transport := buildTransport(cfg.Transport)
+	if cfg.Proxy != nil {
+		transport.Proxy = cfg.Proxy.proxyFunc()
+	}
+	if cfg.TLS == nil {
+		return transport
+	}
+	return newTLSTransport(transport, *cfg.TLS)
+}
+
+// buildTransport clone http.DefaultTransport & apply cfg
+func buildTransport(cfg TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if cfg.DialTimeout > 0 {
+		dialer.Timeout = millis(cfg.DialTimeout)
+	}
+	if cfg.KeepAlive > 0 {
+		dialer.KeepAlive = millis(cfg.KeepAlive)
+	}
+	transport.DialContext = dialer.DialContext
+
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = millis(cfg.IdleConnTimeout)
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = millis(cfg.TLSHandshakeTimeout)
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = millis(cfg.ResponseHeaderTimeout)
+	}
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+	return transport
+}
+
+func millis(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
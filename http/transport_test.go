@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newTransport(t *testing.T) {
+	assert.Nil(t, newTransport(Config{}))
+
+	got, ok := newTransport(Config{Transport: TransportConfig{
+		MaxIdleConns:          50,
+		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       20,
+		IdleConnTimeout:       30000,
+		DialTimeout:           1000,
+		TLSHandshakeTimeout:   2000,
+		ResponseHeaderTimeout: 3000,
+		DisableKeepAlives:     true,
+	}}).(*http.Transport)
+	assert.True(t, ok)
+	assert.Equal(t, 50, got.MaxIdleConns)
+	assert.Equal(t, 10, got.MaxIdleConnsPerHost)
+	assert.Equal(t, 20, got.MaxConnsPerHost)
+	assert.Equal(t, 30*time.Second, got.IdleConnTimeout)
+	assert.Equal(t, 2*time.Second, got.TLSHandshakeTimeout)
+	assert.Equal(t, 3*time.Second, got.ResponseHeaderTimeout)
+	assert.True(t, got.DisableKeepAlives)
+	assert.NotNil(t, got.DialContext)
+	assert.NotNil(t, got.Proxy)
+
+	// unset fields keep default transport values
+	got = newTransport(Config{Transport: TransportConfig{MaxIdleConnsPerHost: 10}}).(*http.Transport)
+	def := http.DefaultTransport.(*http.Transport)
+	assert.Equal(t, def.MaxIdleConns, got.MaxIdleConns)
+	assert.Equal(t, def.IdleConnTimeout, got.IdleConnTimeout)
+	assert.Equal(t, def.TLSHandshakeTimeout, got.TLSHandshakeTimeout)
+	assert.False(t, got.DisableKeepAlives)
+}
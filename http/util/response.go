@@ -1,9 +1,10 @@
 package util
 
 import (
-	"encoding/json"
 	"io/ioutil"
 	"net/http"
+
+	"github.com/cymon1997/go-client/http/codec"
 )
 
 // IsStatusOK check if response code is 2xx
@@ -11,14 +12,14 @@ func IsStatusOK(resp *http.Response) bool {
 	return resp.StatusCode >= 200 && resp.StatusCode < 300
 }
 
-// ParseResponseBody parse response body to dest struct
+// ParseResponseBody parse response body to dest struct using codec of response Content-Type
 func ParseResponseBody(resp *http.Response, dest interface{}) error {
 	raw, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	err = json.Unmarshal(raw, &dest)
+	err = codec.ForResponse(resp).Unmarshal(raw, dest)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,344 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+const (
+	defaultPingInterval = 30 * time.Second
+	defaultPongTimeout  = 10 * time.Second
+)
+
+// ErrWebSocketClosed returned by WebSocket methods after Close
+var ErrWebSocketClosed = errors.New("websocket: closed")
+
+// WebSocketConfig configure WebSocket connections opened by Dial
+type WebSocketConfig struct {
+	// PingInterval between keepalive pings in milliseconds, default 30s, negative disables ping.
+	// Pong is only received while a goroutine is reading, e.g. looping ReadJSON
+	PingInterval int
+	// PongTimeout after which connection without pong is dropped in milliseconds, default 10s
+	PongTimeout int
+	// ReadLimit max size of received message in bytes, default 32KB
+	ReadLimit int64
+	// Reconnect backoff after connection is lost but at least 1s, MaxAttempts (if set) limit consecutive
+	// failed dials, nil means unlimited reconnects starting from 1s up to 30s
+	Reconnect *RetryPolicy
+	// DisableReconnect return connection errors instead of reconnecting
+	DisableReconnect bool
+}
+
+// WebSocket is a connection reconnected automatically when it drops, messages being sent
+// or received while the connection drops may be lost. Read & write methods are safe to call
+// from one reader & one writer goroutine concurrently
+type WebSocket struct {
+	cfg  WebSocketConfig
+	dial func(ctx context.Context) (*websocket.Conn, error)
+	// done is closed by Close to interrupt reconnect
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	gen      uint64
+	closed   bool
+	stopPing context.CancelFunc
+	// reconnecting is closed when the reconnect in progress finishes, nil when none
+	reconnecting chan struct{}
+}
+
+// Dial open WebSocket connection to endpoint using Host with ws or wss scheme, headers,
+// cookies, authenticator, middlewares & transport of the request. Client timeout apply to handshake
+func (r *requestImpl) Dial(ctx context.Context, endpoint string) (*WebSocket, error) {
+	target := r.buildURL(endpoint)
+	if strings.HasPrefix(target, "http") {
+		target = "ws" + strings.TrimPrefix(target, "http")
+	}
+	header := make(http.Header)
+	for k, v := range r.headers {
+		header.Set(k, v)
+	}
+	cookieReq := &http.Request{Header: header}
+	for _, cookie := range r.cookies {
+		cookieReq.AddCookie(cookie)
+	}
+
+	// upgraded connection is the response body, so only the handshake may be timed out
+	client := *r.client
+	client.Timeout = 0
+	doer := chain(authenticate(r.logger.wrap(&client), r.auth), r.middlewares)
+	// websocket.Dial replace HTTPClient of its options, so every dial get its own
+	dialOptions := func() *websocket.DialOptions {
+		return &websocket.DialOptions{
+			HTTPClient: &http.Client{
+				Transport: doerTransport{doer: doer},
+				Timeout:   r.client.Timeout,
+			},
+			HTTPHeader:      header,
+			CompressionMode: websocket.CompressionDisabled,
+		}
+	}
+
+	var cfg WebSocketConfig
+	if r.websocket != nil {
+		cfg = *r.websocket
+	}
+	ws := &WebSocket{
+		cfg:  cfg,
+		done: make(chan struct{}),
+		dial: func(ctx context.Context) (*websocket.Conn, error) {
+			conn, resp, err := websocket.Dial(withRequestInfo(ctx, endpoint, 1, len(r.pathParams) > 0), target, dialOptions())
+			if err != nil {
+				if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+					if httpErr, herr := NewHTTPError(resp); herr == nil {
+						return nil, httpErr
+					}
+				}
+				return nil, err
+			}
+			if cfg.ReadLimit > 0 {
+				conn.SetReadLimit(cfg.ReadLimit)
+			}
+			return conn, nil
+		},
+	}
+	conn, err := ws.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws.setConn(conn)
+	return ws, nil
+}
+
+// ReadJSON receive next message & decode it as JSON into v
+func (w *WebSocket) ReadJSON(ctx context.Context, v interface{}) error {
+	data, err := w.ReadMessage(ctx)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON send v encoded as JSON text message
+func (w *WebSocket) WriteJSON(ctx context.Context, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.WriteMessage(ctx, data)
+}
+
+// ReadMessage receive next text or binary message
+func (w *WebSocket) ReadMessage(ctx context.Context) ([]byte, error) {
+	var data []byte
+	err := w.withConn(ctx, func(conn *websocket.Conn) error {
+		var err error
+		_, data, err = conn.Read(ctx)
+		return err
+	})
+	return data, err
+}
+
+// WriteMessage send data as text message
+func (w *WebSocket) WriteMessage(ctx context.Context, data []byte) error {
+	return w.withConn(ctx, func(conn *websocket.Conn) error {
+		return conn.Write(ctx, websocket.MessageText, data)
+	})
+}
+
+// Close send normal closure & stop reconnecting
+func (w *WebSocket) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	conn, reconnecting := w.conn, w.reconnecting != nil
+	w.stopPing()
+	w.mu.Unlock()
+	if reconnecting {
+		// connection already dropped, reconnect is interrupted by done
+		return nil
+	}
+	return conn.Close(websocket.StatusNormalClosure, "")
+}
+
+// withConn run fn with current connection, reconnecting when the connection is lost
+func (w *WebSocket) withConn(ctx context.Context, fn func(conn *websocket.Conn) error) error {
+	for {
+		w.mu.Lock()
+		conn, gen, closed := w.conn, w.gen, w.closed
+		w.mu.Unlock()
+		if closed {
+			return ErrWebSocketClosed
+		}
+		err := fn(conn)
+		if err == nil || ctx.Err() != nil || w.cfg.DisableReconnect ||
+			websocket.CloseStatus(err) == websocket.StatusNormalClosure {
+			return err
+		}
+		if err = w.reconnect(ctx, gen); err != nil {
+			return err
+		}
+	}
+}
+
+// reconnect replace connection of generation gen, unless another goroutine already did.
+// Only one goroutine dials at a time, others wait for it within their own ctx.
+// w.mu is never held while sleeping or dialing so callers keep honoring their ctx
+func (w *WebSocket) reconnect(ctx context.Context, gen uint64) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWebSocketClosed
+	}
+	if w.gen != gen {
+		w.mu.Unlock()
+		return nil
+	}
+	if wait := w.reconnecting; wait != nil {
+		w.mu.Unlock()
+		select {
+		case <-wait:
+			// connection replaced or reconnect failed, caller retry either way
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.done:
+			return ErrWebSocketClosed
+		}
+	}
+	done := make(chan struct{})
+	w.reconnecting = done
+	w.stopPing()
+	lost := w.conn
+	w.mu.Unlock()
+
+	// failed reads & writes already closed the connection, so this return right away
+	_ = lost.Close(websocket.StatusGoingAway, "")
+	conn, err := w.redial(ctx)
+
+	w.mu.Lock()
+	w.reconnecting = nil
+	close(done)
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	if w.closed {
+		w.mu.Unlock()
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+		return ErrWebSocketClosed
+	}
+	w.setConnLocked(conn)
+	w.mu.Unlock()
+	return nil
+}
+
+// redial dial with backoff until it succeeds, attempts are exhausted, ctx is done or Close is called
+func (w *WebSocket) redial(ctx context.Context) (*websocket.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-w.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	policy := w.cfg.Reconnect
+	if policy == nil {
+		policy = defaultReconnect
+	}
+	for attempt := 1; ; attempt++ {
+		if err := sleep(ctx, reconnectDelay(policy.Backoff(attempt))); err != nil {
+			return nil, w.interrupted(err)
+		}
+		conn, err := w.dial(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, w.interrupted(ctx.Err())
+		}
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return nil, err
+		}
+	}
+}
+
+// interrupted return ErrWebSocketClosed when reconnect is interrupted by Close
+func (w *WebSocket) interrupted(err error) error {
+	select {
+	case <-w.done:
+		return ErrWebSocketClosed
+	default:
+		return err
+	}
+}
+
+func (w *WebSocket) setConn(conn *websocket.Conn) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.setConnLocked(conn)
+}
+
+// setConnLocked install connection & start keepalive, w.mu must be held
+func (w *WebSocket) setConnLocked(conn *websocket.Conn) {
+	w.conn = conn
+	w.gen++
+	ctx, cancel := context.WithCancel(context.Background())
+	w.stopPing = cancel
+
+	interval := time.Duration(w.cfg.PingInterval) * time.Millisecond
+	if interval == 0 {
+		interval = defaultPingInterval
+	}
+	if interval < 0 {
+		return
+	}
+	timeout := time.Duration(w.cfg.PongTimeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultPongTimeout
+	}
+	go keepalive(ctx, conn, interval, timeout)
+}
+
+// keepalive ping conn every interval, Ping drop the connection when pong is not received within timeout
+func keepalive(ctx context.Context, conn *websocket.Conn, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// doerTransport adapt Doer as http.RoundTripper
+type doerTransport struct {
+	doer Doer
+}
+
+func (t doerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.doer.Do(req)
+}
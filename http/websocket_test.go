@@ -0,0 +1,249 @@
+package http
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+type wsMessage struct {
+	Seq  int    `json:"seq"`
+	Text string `json:"text"`
+}
+
+// newWebSocketServer accept connections, handler receive connection number starting from 1
+func newWebSocketServer(t *testing.T, tls bool, handler func(ctx context.Context, n int32, conn *websocket.Conn)) *httptest.Server {
+	var connections int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusGoingAway, "")
+		handler(r.Context(), atomic.AddInt32(&connections, 1), conn)
+	})
+	srv := httptest.NewUnstartedServer(h)
+	if tls {
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Dial(t *testing.T) {
+	srv := newWebSocketServer(t, false, func(ctx context.Context, n int32, conn *websocket.Conn) {
+		for {
+			var msg wsMessage
+			if err := wsjson.Read(ctx, conn, &msg); err != nil {
+				return
+			}
+			msg.Seq++
+			_ = wsjson.Write(ctx, conn, msg)
+		}
+	})
+	var handshake *http.Request
+	c := New(Config{Host: srv.URL, Timeout: 200, Auth: BearerToken("abc")})
+	c.SetBaseHeaders(map[string]string{"X-Client": "go-client"})
+	c.Use(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			handshake = req
+			return next.Do(req)
+		})
+	})
+
+	ws, err := c.WithCookies([]*http.Cookie{{Name: "session", Value: "s1"}}).
+		Dial(context.Background(), "/ws")
+	assert.Nil(t, err)
+	defer ws.Close()
+	assert.Equal(t, "go-client", handshake.Header.Get("X-Client"))
+	assert.Equal(t, "session=s1", handshake.Header.Get("Cookie"))
+	assert.Equal(t, "/ws", EndpointTemplate(handshake.Context()))
+
+	// client timeout only apply to handshake
+	time.Sleep(300 * time.Millisecond)
+	assert.Nil(t, ws.WriteJSON(context.Background(), wsMessage{Seq: 1, Text: "hello"}))
+	var got wsMessage
+	assert.Nil(t, ws.ReadJSON(context.Background(), &got))
+	assert.Equal(t, wsMessage{Seq: 2, Text: "hello"}, got)
+
+	assert.Nil(t, ws.Close())
+	_, err = ws.ReadMessage(context.Background())
+	assert.ErrorIs(t, err, ErrWebSocketClosed)
+
+	// Case handshake rejected
+	_, err = New(Config{Host: srv.URL}).Dial(context.Background(), "/ws")
+	assert.True(t, IsUnauthorized(err))
+}
+
+func TestClient_Dial_TLS(t *testing.T) {
+	srv := newWebSocketServer(t, true, func(ctx context.Context, n int32, conn *websocket.Conn) {
+		_ = wsjson.Write(ctx, conn, wsMessage{Text: "secure"})
+	})
+	c := New(Config{
+		Host: srv.URL,
+		Auth: BearerToken("abc"),
+		TLS: &TLSConfig{
+			CAPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+		},
+	})
+	ws, err := c.Dial(context.Background(), "/ws")
+	assert.Nil(t, err)
+	defer ws.Close()
+	var got wsMessage
+	assert.Nil(t, ws.ReadJSON(context.Background(), &got))
+	assert.Equal(t, "secure", got.Text)
+}
+
+func TestWebSocket_reconnect(t *testing.T) {
+	fastReconnect(t)
+	srv := newWebSocketServer(t, false, func(ctx context.Context, n int32, conn *websocket.Conn) {
+		switch n {
+		case 1:
+			// drop connection abruptly
+			_ = wsjson.Write(ctx, conn, wsMessage{Seq: 1})
+		case 2:
+			// ignore pings so keepalive drop the connection
+			<-ctx.Done()
+		default:
+			_ = wsjson.Write(ctx, conn, wsMessage{Seq: int(n)})
+			_, _, _ = conn.Read(ctx)
+		}
+	})
+
+	c := New(Config{
+		Host: srv.URL,
+		Auth: BearerToken("abc"),
+		WebSocket: &WebSocketConfig{
+			PingInterval: 20,
+			PongTimeout:  20,
+			Reconnect:    &RetryPolicy{BaseDelay: 1, MaxDelay: 5},
+		},
+	})
+	ws, err := c.Dial(context.Background(), "/ws")
+	assert.Nil(t, err)
+	defer ws.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var got wsMessage
+	assert.Nil(t, ws.ReadJSON(ctx, &got))
+	assert.Equal(t, 1, got.Seq)
+	assert.Nil(t, ws.ReadJSON(ctx, &got))
+	assert.Equal(t, 3, got.Seq)
+
+}
+
+func TestWebSocket_reconnectHonorCallerCtx(t *testing.T) {
+	fastReconnect(t)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close(websocket.StatusGoingAway, "")
+	}))
+	defer srv.Close()
+
+	c := New(Config{
+		Host: srv.URL,
+		WebSocket: &WebSocketConfig{
+			PingInterval: -1,
+			Reconnect:    &RetryPolicy{BaseDelay: 10, MaxDelay: 20},
+		},
+	})
+	ws, err := c.Dial(context.Background(), "/ws")
+	assert.Nil(t, err)
+
+	// reader keep reconnecting against unavailable server until Close
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := ws.ReadMessage(context.Background())
+		readErr <- err
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&requests) > 2 }, 3*time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = ws.WriteJSON(ctx, wsMessage{Seq: 1})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.Nil(t, ws.Close())
+	select {
+	case err := <-readErr:
+		assert.ErrorIs(t, err, ErrWebSocketClosed)
+	case <-time.After(time.Second):
+		t.Fatal("reader not interrupted by Close")
+	}
+}
+
+func TestWebSocket_minReconnectDelay(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close(websocket.StatusGoingAway, "")
+	}))
+	defer srv.Close()
+
+	c := New(Config{
+		Host:      srv.URL,
+		WebSocket: &WebSocketConfig{PingInterval: -1, Reconnect: &RetryPolicy{}},
+	})
+	ws, err := c.Dial(context.Background(), "/ws")
+	assert.Nil(t, err)
+	defer ws.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	_, err = ws.ReadMessage(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestWebSocket_disableReconnect(t *testing.T) {
+	srv := newWebSocketServer(t, false, func(ctx context.Context, n int32, conn *websocket.Conn) {
+		_ = wsjson.Write(ctx, conn, wsMessage{Seq: int(n)})
+	})
+	c := New(Config{
+		Host:      srv.URL,
+		Auth:      BearerToken("abc"),
+		WebSocket: &WebSocketConfig{DisableReconnect: true, PingInterval: -1},
+	})
+	ws, err := c.Dial(context.Background(), "/ws")
+	assert.Nil(t, err)
+	defer ws.Close()
+
+	var got wsMessage
+	assert.Nil(t, ws.ReadJSON(context.Background(), &got))
+	assert.Equal(t, 1, got.Seq)
+	_, err = ws.ReadMessage(context.Background())
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrWebSocketClosed)
+}
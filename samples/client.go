@@ -50,6 +50,15 @@ func samples() {
 		return
 	}
 
+	// GET request decoded straight into typed result, body is always closed
+	// non-2xx response returned as *httpClient.HTTPError
+	typed, resp, err := httpClient.GetJSON[Response](ctx, client, "/get", nil)
+	if err != nil {
+		log.Println("error: ", err)
+		return
+	}
+	log.Println(typed.Data)
+
 	// GET request (using auto params)
 	type Params struct {
 		Query string `json:"query"`